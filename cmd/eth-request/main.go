@@ -2,11 +2,13 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/cleanunicorn/ethereum/provider"
 	"github.com/cleanunicorn/ethereum/web3"
@@ -16,11 +18,15 @@ func main() {
 	var (
 		endpointHTTP = flag.String("http", "https://mainnet.infura.io:8545", "HTTP endpoint")
 		method       = flag.String("method", "", "method")
+		timeout      = flag.Duration("timeout", 30*time.Second, "request timeout")
+		attempts     = flag.Int("attempts", 3, "attempts made when the endpoint fails with a transient error")
 	)
 	flag.Parse()
 	args := flag.Args()
 
-	c := web3.NewClient(provider.DialHTTP(*endpointHTTP))
+	c := web3.NewClient(provider.NewRetryProvider(provider.DialHTTP(*endpointHTTP), provider.RetryConfig{
+		MaxAttempts: *attempts,
+	}))
 
 	var params []interface{}
 	switch *method {
@@ -57,7 +63,10 @@ func main() {
 		}
 	}
 
-	response, err := c.Provider.Call(*method, params)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	response, err := c.Provider.CallContext(ctx, *method, params)
 	if err != nil {
 		fmt.Println("Error making request, err: ", err)
 	}
@@ -12,5 +12,9 @@ func Trim0x(prefixedString string) string {
 func HexStrToBigInt(hexString string) *big.Int {
 	value := new(big.Int)
 	value.SetString(Trim0x(hexString), 16)
+	// Parsing keeps an empty slice for zero, the zero value is returned instead
+	if value.Sign() == 0 {
+		return new(big.Int)
+	}
 	return value
 }
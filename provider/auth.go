@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cleanunicorn/ethereum/helper"
+)
+
+// jwtRefresh is how long a JWT is reused, nodes reject tokens issued more than 60s ago
+const jwtRefresh = 30 * time.Second
+
+// WithHeader adds a header to every request.
+func WithHeader(key, value string) HTTPOption {
+	return func(p *HTTPProvider) {
+		p.header.Add(key, value)
+	}
+}
+
+// WithBasicAuth authenticates every request with HTTP basic authentication.
+func WithBasicAuth(username, password string) HTTPOption {
+	return func(p *HTTPProvider) {
+		credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		p.header.Set("Authorization", "Basic "+credentials)
+	}
+}
+
+// WithBearerToken authenticates every request with a static bearer token.
+func WithBearerToken(token string) HTTPOption {
+	return func(p *HTTPProvider) {
+		p.header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithJWTAuth authenticates every request with a HS256 JWT signed by secret,
+// as required by the Engine API. A new token with a fresh "iat" claim is issued
+// every 30 seconds.
+func WithJWTAuth(secret []byte) HTTPOption {
+	return func(p *HTTPProvider) {
+		p.jwt = &jwtAuth{
+			secret: secret,
+		}
+	}
+}
+
+// ReadJWTSecret reads the hex encoded 32 bytes secret shared with the node, such as
+// the file given to geth with --authrpc.jwtsecret.
+func ReadJWTSecret(path string) ([]byte, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := hex.DecodeString(helper.Trim0x(strings.TrimSpace(string(content))))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT secret in %s: %s", path, err)
+	}
+	if len(secret) != 32 {
+		return nil, fmt.Errorf("invalid JWT secret in %s: got %d bytes, want 32", path, len(secret))
+	}
+
+	return secret, nil
+}
+
+// jwtAuth issues the tokens of WithJWTAuth, reusing them while they are fresh.
+type jwtAuth struct {
+	secret []byte
+
+	mu       sync.Mutex
+	current  string
+	issuedAt time.Time
+}
+
+func (a *jwtAuth) token() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	if a.current != "" && now.Sub(a.issuedAt) < jwtRefresh {
+		return a.current, nil
+	}
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	claims := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"iat":%d}`, now.Unix())))
+	mac := hmac.New(sha256.New, a.secret)
+	if _, err := mac.Write([]byte(header + "." + claims)); err != nil {
+		return "", err
+	}
+	signature := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	a.current = header + "." + claims + "." + signature
+	a.issuedAt = now
+
+	return a.current, nil
+}
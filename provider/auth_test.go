@@ -0,0 +1,117 @@
+package provider_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cleanunicorn/ethereum/provider"
+)
+
+func TestDialHTTP_Auth(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []provider.HTTPOption
+		check   func(t *testing.T, r *http.Request)
+	}{
+		{
+			name:    "Static header",
+			options: []provider.HTTPOption{provider.WithHeader("X-Api-Key", "key")},
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.Header.Get("X-Api-Key"); got != "key" {
+					t.Errorf("X-Api-Key = %q, want key", got)
+				}
+			},
+		},
+		{
+			name:    "Basic auth",
+			options: []provider.HTTPOption{provider.WithBasicAuth("user", "pass")},
+			check: func(t *testing.T, r *http.Request) {
+				username, password, ok := r.BasicAuth()
+				if !ok || username != "user" || password != "pass" {
+					t.Errorf("BasicAuth() = %s, %s, %v, want user, pass", username, password, ok)
+				}
+			},
+		},
+		{
+			name:    "Bearer token",
+			options: []provider.HTTPOption{provider.WithBearerToken("token")},
+			check: func(t *testing.T, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != "Bearer token" {
+					t.Errorf("Authorization = %q, want Bearer token", got)
+				}
+			},
+		},
+		{
+			name:    "JWT",
+			options: []provider.HTTPOption{provider.WithJWTAuth(jwtSecret)},
+			check:   checkJWT,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.check(t, r)
+				w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+			}))
+			defer server.Close()
+
+			if _, err := provider.DialHTTP(server.URL, tt.options...).Call("eth_blockNumber", []interface{}{}); err != nil {
+				t.Errorf("HTTPProvider.Call() error = %v", err)
+			}
+		})
+	}
+}
+
+var jwtSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func checkJWT(t *testing.T, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("JWT %q does not have 3 parts", token)
+	}
+
+	mac := hmac.New(sha256.New, jwtSecret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if base64.RawURLEncoding.EncodeToString(mac.Sum(nil)) != parts[2] {
+		t.Errorf("JWT signature is invalid")
+	}
+
+	claimsJSON, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims struct {
+		IssuedAt int64 `json:"iat"`
+	}
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		t.Fatalf("Could not decode claims %s, err: %s", claimsJSON, err)
+	}
+	if d := time.Now().Unix() - claims.IssuedAt; d < 0 || d > 5 {
+		t.Errorf("JWT iat is %ds old", d)
+	}
+}
+
+func TestReadJWTSecret(t *testing.T) {
+	f, err := ioutil.TempFile("", "jwtsecret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("0x3031323334353637383961626364656630313233343536373839616263646566\n")
+	f.Close()
+
+	secret, err := provider.ReadJWTSecret(f.Name())
+	if err != nil {
+		t.Fatalf("ReadJWTSecret() error = %v", err)
+	}
+	if string(secret) != string(jwtSecret) {
+		t.Errorf("ReadJWTSecret() = %x, want %x", secret, jwtSecret)
+	}
+}
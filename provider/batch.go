@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrMissingResponse is set on a batch element the node did not answer.
+var ErrMissingResponse = errors.New("provider: missing response in batch")
+
+// BatchElem is a single request of a batch.
+type BatchElem struct {
+	Method string
+	Params interface{}
+	// Result is the raw JSON-RPC response, set when the request succeeded
+	Result []byte
+	// Error is set when the request failed
+	Error error
+}
+
+// BatchCaller is implemented by the providers able to send several requests at once.
+//
+// BatchCallContext returns an error only if the batch as a whole failed, the outcome of
+// each request is stored in its element.
+type BatchCaller interface {
+	BatchCallContext(ctx context.Context, batch []BatchElem) error
+}
+
+// BatchCall sends the batch through p.
+// Providers not implementing BatchCaller get the requests one after the other.
+func BatchCall(p Provider, batch []BatchElem) error {
+	return BatchCallContext(context.Background(), p, batch)
+}
+
+// BatchCallContext sends the batch through p, giving up when the context is done.
+// Providers not implementing BatchCaller get the requests one after the other.
+func BatchCallContext(ctx context.Context, p Provider, batch []BatchElem) error {
+	if b, ok := p.(BatchCaller); ok {
+		return b.BatchCallContext(ctx, batch)
+	}
+
+	for i := range batch {
+		batch[i].Result, batch[i].Error = p.CallContext(ctx, batch[i].Method, batch[i].Params)
+	}
+
+	return nil
+}
+
+// BatchCall sends all the requests in a single HTTP POST.
+//
+// Responses are matched to their requests by ID, they may be returned in any order.
+func (c *HTTPProvider) BatchCall(batch []BatchElem) error {
+	return c.BatchCallContext(context.Background(), batch)
+}
+
+// BatchCallContext sends all the requests in a single HTTP POST, canceled when the context is done.
+// Responses are matched to the requests by id, the elements left unanswered get ErrMissingResponse.
+func (c *HTTPProvider) BatchCallContext(ctx context.Context, batch []BatchElem) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	// Reserve a range of ids, the element i gets the id first+i
+	first := atomic.AddUint64(&c.nextID, uint64(len(batch))) - uint64(len(batch)) + 1
+	data := make([]map[string]interface{}, len(batch))
+	for i, elem := range batch {
+		data[i] = map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  elem.Method,
+			"params":  elem.Params,
+			"id":      first + uint64(i),
+		}
+	}
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := c.startSpan(ctx, "batch", 0)
+	span.set(AttributeBatchSize, len(batch))
+	spans := make([]*callSpan, len(batch))
+	for i, elem := range batch {
+		_, spans[i] = c.startSpan(ctx, elem.Method, first+uint64(i))
+	}
+	err = c.batch(ctx, span, dataJSON, batch, first)
+	for i := range batch {
+		if err != nil {
+			spans[i].end(err)
+			continue
+		}
+		spans[i].end(batch[i].Error)
+	}
+	span.end(err)
+
+	return err
+}
+
+// batch posts the encoded batch and stores the responses in the elements,
+// the element i answering the request with the id first+i.
+func (c *HTTPProvider) batch(ctx context.Context, span *callSpan, dataJSON []byte, batch []BatchElem, first uint64) error {
+	body, err := c.post(ctx, span, dataJSON)
+	if err != nil {
+		return err
+	}
+
+	var responses []json.RawMessage
+	if err := json.Unmarshal(body, &responses); err != nil {
+		// The node refused the whole batch
+		if respErr := checkResponseError(body); respErr != nil {
+			return respErr
+		}
+		return fmt.Errorf("invalid batch response: %s", err)
+	}
+
+	answered := make([]bool, len(batch))
+	for _, response := range responses {
+		var resp struct {
+			ID uint64 `json:"id"`
+		}
+		if err := json.Unmarshal(response, &resp); err != nil || resp.ID < first {
+			continue
+		}
+		i := resp.ID - first
+		if i >= uint64(len(batch)) || answered[i] {
+			continue
+		}
+		answered[i] = true
+
+		if err := checkResponseError(response); err != nil {
+			batch[i].Error = err
+			continue
+		}
+		batch[i].Result = response
+	}
+
+	for i := range batch {
+		if !answered[i] {
+			batch[i].Error = ErrMissingResponse
+		}
+	}
+
+	return nil
+}
+
+// batchCall sends the requests concurrently, they share the connection so the batch
+// takes about one round-trip.
+func (c *stream) batchCall(ctx context.Context, batch []BatchElem) error {
+	var wg sync.WaitGroup
+	for i := range batch {
+		wg.Add(1)
+		go func(elem *BatchElem) {
+			defer wg.Done()
+			elem.Result, elem.Error = c.call(ctx, elem.Method, elem.Params, nil)
+		}(&batch[i])
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// BatchCall sends all the requests without waiting for the previous responses.
+func (c *WebSocketProvider) BatchCall(batch []BatchElem) error {
+	return c.stream.batchCall(context.Background(), batch)
+}
+
+// BatchCallContext sends all the requests without waiting for the previous responses,
+// giving up when the context is done.
+func (c *WebSocketProvider) BatchCallContext(ctx context.Context, batch []BatchElem) error {
+	return c.stream.batchCall(ctx, batch)
+}
+
+// BatchCall sends all the requests without waiting for the previous responses.
+func (c *IPCProvider) BatchCall(batch []BatchElem) error {
+	return c.stream.batchCall(context.Background(), batch)
+}
+
+// BatchCallContext sends all the requests without waiting for the previous responses,
+// giving up when the context is done.
+func (c *IPCProvider) BatchCallContext(ctx context.Context, batch []BatchElem) error {
+	return c.stream.batchCall(ctx, batch)
+}
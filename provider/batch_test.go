@@ -0,0 +1,79 @@
+package provider_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cleanunicorn/ethereum/provider"
+)
+
+var _ provider.BatchCaller = (*provider.HTTPProvider)(nil)
+
+func TestHTTPProvider_BatchCall(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := ioutil.ReadAll(r.Body)
+		var batch []struct {
+			ID     int      `json:"id"`
+			Method string   `json:"method"`
+			Params []string `json:"params"`
+		}
+		if err := json.Unmarshal(body, &batch); err != nil {
+			t.Fatalf("Could not decode batch, err: %s", err)
+		}
+
+		// Answer in reverse order, skip the last request and fail the "0xbad" ones
+		var responses []string
+		for i := len(batch) - 2; i >= 0; i-- {
+			if batch[i].Params[0] == "0xbad" {
+				responses = append(responses, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-32000,"message":"bad"}}`, batch[i].ID))
+				continue
+			}
+			responses = append(responses, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"%s"}`, batch[i].ID, batch[i].Params[0]))
+		}
+		var raw []json.RawMessage
+		for _, r := range responses {
+			raw = append(raw, json.RawMessage(r))
+		}
+		out, _ := json.Marshal(raw)
+		w.Write(out)
+	}))
+	defer server.Close()
+
+	batch := []provider.BatchElem{
+		{Method: "eth_getTransactionReceipt", Params: []interface{}{"0x1"}},
+		{Method: "eth_getTransactionReceipt", Params: []interface{}{"0xbad"}},
+		{Method: "eth_getTransactionReceipt", Params: []interface{}{"0x3"}},
+		{Method: "eth_getTransactionReceipt", Params: []interface{}{"0x4"}},
+	}
+	if err := provider.BatchCall(provider.DialHTTP(server.URL), batch); err != nil {
+		t.Fatalf("BatchCall() error = %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("BatchCall() made %d requests, want 1", requests)
+	}
+
+	for i, want := range []string{"0x1", "", "0x3", ""} {
+		if want == "" {
+			if batch[i].Error == nil {
+				t.Errorf("batch[%d].Error = nil, want error", i)
+			}
+			continue
+		}
+		var resp struct {
+			Result string `json:"result"`
+		}
+		json.Unmarshal(batch[i].Result, &resp)
+		if batch[i].Error != nil || resp.Result != want {
+			t.Errorf("batch[%d] = %s, %v, want %s", i, batch[i].Result, batch[i].Error, want)
+		}
+	}
+	if batch[3].Error != provider.ErrMissingResponse {
+		t.Errorf("batch[3].Error = %v, want %v", batch[3].Error, provider.ErrMissingResponse)
+	}
+}
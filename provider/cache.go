@@ -0,0 +1,347 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cleanunicorn/ethereum/helper"
+)
+
+// Default cache parameters
+const (
+	defaultCacheSize          = 1024
+	defaultCacheConfirmations = 12
+	// cacheHeadRefresh limits how often the latest block number is fetched to check the finality
+	cacheHeadRefresh = 2 * time.Second
+)
+
+// cacheByHash lists the methods identifying their target by hash, their result never changes once found
+var cacheByHash = map[string]bool{
+	"eth_getBlockByHash":                    true,
+	"eth_getBlockTransactionCountByHash":    true,
+	"eth_getUncleCountByBlockHash":          true,
+	"eth_getTransactionByBlockHashAndIndex": true,
+	"eth_getUncleByBlockHashAndIndex":       true,
+	"net_version":                           true,
+	"eth_chainId":                           true,
+}
+
+// cacheByBlockParam lists the methods reading the state at a block, with the position of the block parameter
+var cacheByBlockParam = map[string]int{
+	"eth_getBalance":                          1,
+	"eth_getCode":                             1,
+	"eth_getTransactionCount":                 1,
+	"eth_getStorageAt":                        2,
+	"eth_call":                                1,
+	"eth_getBlockByNumber":                    0,
+	"eth_getBlockTransactionCountByNumber":    0,
+	"eth_getUncleCountByBlockNumber":          0,
+	"eth_getTransactionByBlockNumberAndIndex": 0,
+	"eth_getUncleByBlockNumberAndIndex":       0,
+}
+
+// cacheByResultBlock lists the methods whose result is final once the block containing it is
+var cacheByResultBlock = map[string]bool{
+	"eth_getTransactionByHash":  true,
+	"eth_getTransactionReceipt": true,
+}
+
+// CacheConfig configures a CacheProvider.
+type CacheConfig struct {
+	// Memory is the first level cache, an LRUCache of 1024 entries by default
+	Memory Cache
+	// Disk is an optional second level cache, such as a DiskCache, filled along the first one
+	Disk Cache
+	// Confirmations is the number of blocks mined on top of a block before results at
+	// that block are cached, to survive reorganizations. 0 selects the default of 12,
+	// a negative value caches the results as soon as the block is mined.
+	Confirmations int
+}
+
+// CacheProvider wraps a provider and caches the results which can not change:
+// blocks by hash, receipts of finalized transactions, state at a finalized block.
+//
+// Results at the "latest", "pending", "safe" and "finalized" tags and empty results are never cached.
+type CacheProvider struct {
+	provider Provider
+	config   CacheConfig
+
+	mu        sync.Mutex
+	head      uint64
+	headFetch time.Time
+}
+
+// NewCacheProvider wraps p, caching its results as configured.
+func NewCacheProvider(p Provider, config CacheConfig) *CacheProvider {
+	if config.Memory == nil {
+		config.Memory = NewLRUCache(defaultCacheSize)
+	}
+	if config.Confirmations == 0 {
+		config.Confirmations = defaultCacheConfirmations
+	}
+	if config.Confirmations < 0 {
+		config.Confirmations = 0
+	}
+
+	return &CacheProvider{
+		provider: p,
+		config:   config,
+	}
+}
+
+// Call makes a request with a specified method and parameters.
+func (c *CacheProvider) Call(method string, params interface{}) ([]byte, error) {
+	return c.CallContext(context.Background(), method, params)
+}
+
+// CallContext returns the cached response of the call or makes the request,
+// caching its response if it can not change.
+func (c *CacheProvider) CallContext(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	key, cacheable := c.key(method, params)
+	if cacheable {
+		if reply, ok := c.get(key); ok {
+			return reply, nil
+		}
+	}
+
+	reply, err := c.provider.CallContext(ctx, method, params)
+	if err != nil {
+		return reply, err
+	}
+
+	if cacheable && c.final(ctx, method, params, reply) {
+		c.set(key, reply)
+	}
+
+	return reply, nil
+}
+
+// RawCall calls a method with a JSON encoded list of params.
+func (c *CacheProvider) RawCall(method string, args []interface{}) ([]byte, error) {
+	return c.CallContext(context.Background(), method, args)
+}
+
+// BatchCallContext answers the cached requests and sends the others in a single batch.
+func (c *CacheProvider) BatchCallContext(ctx context.Context, batch []BatchElem) error {
+	keys := make([]string, len(batch))
+	var missing []int
+	for i, elem := range batch {
+		key, cacheable := c.key(elem.Method, elem.Params)
+		if cacheable {
+			if reply, ok := c.get(key); ok {
+				batch[i].Result, batch[i].Error = reply, nil
+				continue
+			}
+			keys[i] = key
+		}
+		missing = append(missing, i)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	sub := make([]BatchElem, len(missing))
+	for j, i := range missing {
+		sub[j] = BatchElem{
+			Method: batch[i].Method,
+			Params: batch[i].Params,
+		}
+	}
+	if err := BatchCallContext(ctx, c.provider, sub); err != nil {
+		return err
+	}
+
+	for j, i := range missing {
+		batch[i].Result, batch[i].Error = sub[j].Result, sub[j].Error
+		if keys[i] != "" && sub[j].Error == nil && c.final(ctx, sub[j].Method, sub[j].Params, sub[j].Result) {
+			c.set(keys[i], sub[j].Result)
+		}
+	}
+
+	return nil
+}
+
+// SubscribeContext subscribes through the wrapped provider, notifications are not cached.
+func (c *CacheProvider) SubscribeContext(ctx context.Context, args ...interface{}) (*Subscription, error) {
+	subscriber, ok := c.provider.(Subscriber)
+	if !ok {
+		return nil, ErrSubscriptionNotSupported
+	}
+
+	return subscriber.SubscribeContext(ctx, args...)
+}
+
+// Close closes the wrapped provider.
+func (c *CacheProvider) Close() error {
+	return c.provider.Close()
+}
+
+// key returns the cache key of the call and whether its result may be cached.
+func (c *CacheProvider) key(method string, params interface{}) (string, bool) {
+	_, byHash := cacheByHash[method]
+	_, byBlockParam := cacheByBlockParam[method]
+	_, byResultBlock := cacheByResultBlock[method]
+	if !byHash && !byBlockParam && !byResultBlock {
+		return "", false
+	}
+
+	if byBlockParam {
+		if _, ok := blockParam(method, params); !ok {
+			return "", false
+		}
+	}
+
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return "", false
+	}
+
+	return method + string(paramsJSON), true
+}
+
+// final reports whether the successful reply of a cacheable call can not change anymore.
+func (c *CacheProvider) final(ctx context.Context, method string, params interface{}, reply []byte) bool {
+	var response struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(reply, &response); err != nil {
+		return false
+	}
+	// Not found yet
+	if len(response.Result) == 0 || string(response.Result) == "null" {
+		return false
+	}
+
+	if cacheByHash[method] {
+		return true
+	}
+
+	if cacheByResultBlock[method] {
+		var result struct {
+			BlockNumber *string `json:"blockNumber"`
+		}
+		if err := json.Unmarshal(response.Result, &result); err != nil || result.BlockNumber == nil {
+			// Still pending
+			return false
+		}
+		return c.deepEnough(ctx, helper.HexStrToBigInt(*result.BlockNumber).Uint64())
+	}
+
+	block, _ := blockParam(method, params)
+	if block.hash {
+		return true
+	}
+
+	return c.deepEnough(ctx, block.number)
+}
+
+// deepEnough reports whether enough blocks were mined on top of the block number.
+func (c *CacheProvider) deepEnough(ctx context.Context, number uint64) bool {
+	confirmations := uint64(c.config.Confirmations)
+
+	c.mu.Lock()
+	head, fetched := c.head, c.headFetch
+	c.mu.Unlock()
+	if number+confirmations <= head {
+		return true
+	}
+	if time.Since(fetched) < cacheHeadRefresh {
+		return false
+	}
+
+	reply, err := c.provider.CallContext(ctx, "eth_blockNumber", []interface{}{})
+	if err != nil {
+		return false
+	}
+	var response struct {
+		Result string `json:"result"`
+	}
+	if err := json.Unmarshal(reply, &response); err != nil {
+		return false
+	}
+	head = helper.HexStrToBigInt(response.Result).Uint64()
+
+	c.mu.Lock()
+	c.head, c.headFetch = head, time.Now()
+	c.mu.Unlock()
+
+	return number+confirmations <= head
+}
+
+func (c *CacheProvider) get(key string) ([]byte, bool) {
+	if reply, ok := c.config.Memory.Get(key); ok {
+		return reply, true
+	}
+	if c.config.Disk == nil {
+		return nil, false
+	}
+
+	reply, ok := c.config.Disk.Get(key)
+	if ok {
+		c.config.Memory.Set(key, reply)
+	}
+
+	return reply, ok
+}
+
+func (c *CacheProvider) set(key string, reply []byte) {
+	c.config.Memory.Set(key, reply)
+	if c.config.Disk != nil {
+		c.config.Disk.Set(key, reply)
+	}
+}
+
+type blockRef struct {
+	hash   bool
+	number uint64
+}
+
+// blockParam returns the block targeted by a call reading the state at a block.
+// Calls at a block tag other than "earliest" are not fixed to a block.
+func blockParam(method string, params interface{}) (blockRef, bool) {
+	position, ok := cacheByBlockParam[method]
+	if !ok {
+		return blockRef{}, false
+	}
+
+	// Go through JSON to support any params type
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return blockRef{}, false
+	}
+	var list []json.RawMessage
+	if err := json.Unmarshal(paramsJSON, &list); err != nil || position >= len(list) {
+		return blockRef{}, false
+	}
+
+	var tag string
+	if err := json.Unmarshal(list[position], &tag); err == nil {
+		switch {
+		case tag == "earliest":
+			return blockRef{number: 0}, true
+		case strings.HasPrefix(tag, "0x"):
+			return blockRef{number: helper.HexStrToBigInt(tag).Uint64()}, true
+		}
+		return blockRef{}, false
+	}
+
+	// EIP-1898 block parameter
+	var object struct {
+		BlockHash   string `json:"blockHash"`
+		BlockNumber string `json:"blockNumber"`
+	}
+	if err := json.Unmarshal(list[position], &object); err != nil {
+		return blockRef{}, false
+	}
+	if object.BlockHash != "" {
+		return blockRef{hash: true}, true
+	}
+	if strings.HasPrefix(object.BlockNumber, "0x") {
+		return blockRef{number: helper.HexStrToBigInt(object.BlockNumber).Uint64()}, true
+	}
+
+	return blockRef{}, false
+}
@@ -0,0 +1,193 @@
+package provider_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/cleanunicorn/ethereum/provider"
+)
+
+// startCountingNode starts a stand-in node at block 0x100 counting the calls of each method.
+func startCountingNode() (*httptest.Server, func(method string) int) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req struct {
+			ID     uint64        `json:"id"`
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+		}
+		json.Unmarshal(body, &req)
+		mu.Lock()
+		calls[req.Method]++
+		mu.Unlock()
+
+		result := `"0x1"`
+		switch req.Method {
+		case "eth_blockNumber":
+			result = `"0x100"`
+		case "eth_getBlockByHash":
+			result = `{"hash":"0xaa","number":"0xfe"}`
+		case "eth_getTransactionReceipt":
+			switch req.Params[0] {
+			case "0xold":
+				result = `{"blockNumber":"0x10","status":"0x1"}`
+			case "0xrecent":
+				result = `{"blockNumber":"0xfe","status":"0x1"}`
+			default:
+				result = `null`
+			}
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":%s}`, req.ID, result)
+	}))
+
+	return server, func(method string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls[method]
+	}
+}
+
+func TestCacheProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		params    []interface{}
+		wantCalls int
+	}{
+		{
+			name:      "Block by hash is cached",
+			method:    "eth_getBlockByHash",
+			params:    []interface{}{"0xaa", false},
+			wantCalls: 1,
+		},
+		{
+			name:      "Balance at latest is not cached",
+			method:    "eth_getBalance",
+			params:    []interface{}{"0x0", "latest"},
+			wantCalls: 3,
+		},
+		{
+			name:      "Balance at pending is not cached",
+			method:    "eth_getBalance",
+			params:    []interface{}{"0x0", "pending"},
+			wantCalls: 3,
+		},
+		{
+			name:      "Code at a finalized block is cached",
+			method:    "eth_getCode",
+			params:    []interface{}{"0x0", "0x1"},
+			wantCalls: 1,
+		},
+		{
+			name:      "Code at a recent block is not cached",
+			method:    "eth_getCode",
+			params:    []interface{}{"0x0", "0xff"},
+			wantCalls: 3,
+		},
+		{
+			name:      "Code at a block hash is cached",
+			method:    "eth_getCode",
+			params:    []interface{}{"0x0", map[string]string{"blockHash": "0xaa"}},
+			wantCalls: 1,
+		},
+		{
+			name:      "Receipt of a finalized transaction is cached",
+			method:    "eth_getTransactionReceipt",
+			params:    []interface{}{"0xold"},
+			wantCalls: 1,
+		},
+		{
+			name:      "Receipt of a recent transaction is not cached",
+			method:    "eth_getTransactionReceipt",
+			params:    []interface{}{"0xrecent"},
+			wantCalls: 3,
+		},
+		{
+			name:      "Missing receipt is not cached",
+			method:    "eth_getTransactionReceipt",
+			params:    []interface{}{"0xunknown"},
+			wantCalls: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := startCountingNode()
+			defer server.Close()
+
+			p := provider.NewCacheProvider(provider.DialHTTP(server.URL), provider.CacheConfig{})
+			for i := 0; i < 3; i++ {
+				if _, err := p.Call(tt.method, tt.params); err != nil {
+					t.Fatalf("CacheProvider.Call() error = %v", err)
+				}
+			}
+			if got := calls(tt.method); got != tt.wantCalls {
+				t.Errorf("%s reached the node %d times, want %d", tt.method, got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCacheProvider_Disk(t *testing.T) {
+	server, calls := startCountingNode()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i := 0; i < 2; i++ {
+		disk, err := provider.NewDiskCache(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// A new provider with an empty memory cache, as after a restart
+		p := provider.NewCacheProvider(provider.DialHTTP(server.URL), provider.CacheConfig{Disk: disk})
+		if _, err := p.Call("eth_getBlockByHash", []interface{}{"0xaa", true}); err != nil {
+			t.Fatalf("CacheProvider.Call() error = %v", err)
+		}
+	}
+	if got := calls("eth_getBlockByHash"); got != 1 {
+		t.Errorf("eth_getBlockByHash reached the node %d times, want 1", got)
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	c := provider.NewLRUCache(2)
+	c.Set("a", []byte("1"))
+	c.Set("b", []byte("2"))
+	c.Get("a")
+	c.Set("c", []byte("3"))
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("LRUCache kept the least recently used entry")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("LRUCache evicted %s", key)
+		}
+	}
+}
+
+func TestLRUCache_Unbounded(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		c := provider.NewLRUCache(size)
+		c.Set("a", []byte("1"))
+		c.Set("b", []byte("2"))
+
+		for _, key := range []string{"a", "b"} {
+			if _, ok := c.Get(key); !ok {
+				t.Errorf("LRUCache of size %d evicted %s", size, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cleanunicorn/ethereum/helper"
+)
+
+// Common node errors, an RPCError matches them with errors.Is.
+var (
+	ErrNonceTooLow            = errors.New("nonce too low")
+	ErrReplacementUnderpriced = errors.New("replacement transaction underpriced")
+	ErrInsufficientFunds      = errors.New("insufficient funds")
+	ErrExecutionReverted      = errors.New("execution reverted")
+	// ErrRangeTooLarge is returned by the nodes limiting the blocks or the logs of an eth_getLogs query
+	ErrRangeTooLarge = errors.New("block range too large")
+	// ErrFilterNotFound is returned when polling a filter the node uninstalled or expired
+	ErrFilterNotFound = errors.New("filter not found")
+)
+
+// errorClasses lists the messages used by the different node implementations for each common error
+var errorClasses = map[error][]string{
+	ErrNonceTooLow: {
+		"nonce too low",
+		"nonce is too low",
+		"doesn't have the correct nonce",
+	},
+	ErrReplacementUnderpriced: {
+		"replacement transaction underpriced",
+		"gas price is too low. there is another transaction with same nonce",
+	},
+	ErrInsufficientFunds: {
+		"insufficient funds",
+	},
+	ErrExecutionReverted: {
+		"execution reverted",
+		"vm exception while processing transaction: revert",
+	},
+	ErrRangeTooLarge: {
+		"block range too large",
+		"block range is too wide",
+		"exceed maximum block range",
+		"query returned more than",
+		"log response size exceeded",
+	},
+	ErrFilterNotFound: {
+		"filter not found",
+	},
+}
+
+// revertSelector is the selector of Error(string), used by solidity to encode the revert reason
+var revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+// RPCError is the error object of a JSON-RPC response.
+//
+// Use errors.As to get it from the error returned by a call.
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("code: %d, error: %s", e.Code, e.Message)
+}
+
+// Is reports whether the error belongs to one of the common node errors
+//
+//	errors.Is(err, provider.ErrNonceTooLow)
+func (e *RPCError) Is(target error) bool {
+	// The target is compared rather than looked up, indexing the map with a
+	// non-comparable error would panic
+	for class, messages := range errorClasses {
+		if target != class {
+			continue
+		}
+
+		message := strings.ToLower(e.Message)
+		for _, m := range messages {
+			if strings.Contains(message, m) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return false
+}
+
+// RevertData returns the data returned by a reverted execution.
+// The second value is false if the node did not send any.
+func (e *RPCError) RevertData() ([]byte, bool) {
+	var data string
+	if err := json.Unmarshal(e.Data, &data); err != nil || !strings.HasPrefix(data, "0x") {
+		return nil, false
+	}
+
+	revert, err := hex.DecodeString(helper.Trim0x(data))
+	if err != nil {
+		return nil, false
+	}
+
+	return revert, true
+}
+
+// RevertReason returns the reason string of a reverted execution, decoded from
+// the Error(string) revert data.
+// The second value is false if the revert data does not contain a reason.
+func (e *RPCError) RevertReason() (string, bool) {
+	data, ok := e.RevertData()
+	if !ok || len(data) < 4+64 || string(data[:4]) != string(revertSelector) {
+		return "", false
+	}
+	data = data[4:]
+
+	// The string is encoded as its offset, its length and its padded content
+	offset := binary.BigEndian.Uint64(data[24:32])
+	if offset > uint64(len(data)-32) {
+		return "", false
+	}
+	length := binary.BigEndian.Uint64(data[offset+24 : offset+32])
+	if length > uint64(len(data))-offset-32 {
+		return "", false
+	}
+
+	return string(data[offset+32 : offset+32+length]), true
+}
+
+// HTTPError is returned when the endpoint answers with an HTTP error status
+// and no JSON-RPC error.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Body       []byte
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("http status: %s, body: %s", e.Status, e.Body)
+}
+
+// IDMismatchError is returned when the response does not carry the id of the request,
+// it answers another request.
+type IDMismatchError struct {
+	Want uint64
+	Got  json.RawMessage
+}
+
+func (e *IDMismatchError) Error() string {
+	return fmt.Sprintf("response id %s does not match request id %d", e.Got, e.Want)
+}
@@ -0,0 +1,130 @@
+package provider_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cleanunicorn/ethereum/provider"
+)
+
+func TestRPCError(t *testing.T) {
+	// Error(string) encoding of "not enough"
+	revertData := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"000000000000000000000000000000000000000000000000000000000000000a" +
+		"6e6f7420656e6f75676800000000000000000000000000000000000000000000"
+
+	tests := []struct {
+		name       string
+		response   string
+		wantCode   int
+		wantIs     error
+		wantReason string
+	}{
+		{
+			name:     "Geth nonce too low",
+			response: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"nonce too low"}}`,
+			wantCode: -32000,
+			wantIs:   provider.ErrNonceTooLow,
+		},
+		{
+			name:     "Parity nonce too low",
+			response: `{"jsonrpc":"2.0","id":1,"error":{"code":-32010,"message":"Transaction nonce is too low. Try incrementing the nonce."}}`,
+			wantCode: -32010,
+			wantIs:   provider.ErrNonceTooLow,
+		},
+		{
+			name:     "Replacement underpriced",
+			response: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"replacement transaction underpriced"}}`,
+			wantCode: -32000,
+			wantIs:   provider.ErrReplacementUnderpriced,
+		},
+		{
+			name:     "Insufficient funds",
+			response: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"insufficient funds for gas * price + value"}}`,
+			wantCode: -32000,
+			wantIs:   provider.ErrInsufficientFunds,
+		},
+		{
+			name:       "Execution reverted with reason",
+			response:   `{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted: not enough","data":"` + revertData + `"}}`,
+			wantCode:   3,
+			wantIs:     provider.ErrExecutionReverted,
+			wantReason: "not enough",
+		},
+		{
+			name:     "Geth too many logs",
+			response: `{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"query returned more than 10000 results"}}`,
+			wantCode: -32005,
+			wantIs:   provider.ErrRangeTooLarge,
+		},
+		{
+			name:     "Block range limit",
+			response: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"exceed maximum block range: 5000"}}`,
+			wantCode: -32000,
+			wantIs:   provider.ErrRangeTooLarge,
+		},
+		{
+			name:     "Filter expired",
+			response: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"filter not found"}}`,
+			wantCode: -32000,
+			wantIs:   provider.ErrFilterNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			_, err := provider.DialHTTP(server.URL).Call("eth_sendRawTransaction", []interface{}{"0x"})
+
+			var rpcErr *provider.RPCError
+			if !errors.As(err, &rpcErr) {
+				t.Fatalf("Call() error = %v, want *provider.RPCError", err)
+			}
+			if rpcErr.Code != tt.wantCode {
+				t.Errorf("RPCError.Code = %d, want %d", rpcErr.Code, tt.wantCode)
+			}
+			if !errors.Is(err, tt.wantIs) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", err, tt.wantIs)
+			}
+
+			reason, ok := rpcErr.RevertReason()
+			if ok != (tt.wantReason != "") || reason != tt.wantReason {
+				t.Errorf("RPCError.RevertReason() = %q, %v, want %q", reason, ok, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestRPCError_Data(t *testing.T) {
+	e := provider.RPCError{}
+	if err := json.Unmarshal([]byte(`{"code":3,"message":"execution reverted","data":"0xdeadbeef"}`), &e); err != nil {
+		t.Fatal(err)
+	}
+	data, ok := e.RevertData()
+	if !ok || len(data) != 4 || data[0] != 0xde {
+		t.Errorf("RPCError.RevertData() = %x, %v, want deadbeef", data, ok)
+	}
+	if errors.Is(&e, provider.ErrNonceTooLow) {
+		t.Errorf("errors.Is(%v, %v) = true, want false", &e, provider.ErrNonceTooLow)
+	}
+}
+
+// sliceError is an error type which can not be compared
+type sliceError []string
+
+func (e sliceError) Error() string { return strings.Join(e, ", ") }
+
+func TestRPCError_IsNotComparable(t *testing.T) {
+	err := &provider.RPCError{Code: -32000, Message: "nonce too low"}
+	if errors.Is(err, sliceError{"nonce too low"}) {
+		t.Errorf("errors.Is() = true for a non-comparable target")
+	}
+}
@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+)
+
+// HTTPProvider encapsulates the HTTP client that will be used for calls
+type HTTPProvider struct {
+	HTTPClient   *http.Client
+	HTTPEndpoint string
+
+	header          http.Header
+	jwt             *jwtAuth
+	gzip            bool
+	maxResponseSize int64
+	tracer          Tracer
+	// sharedTransport is set while the transport is the one of the client given to
+	// WithHTTPClient, it is cloned before being configured
+	sharedTransport bool
+
+	// nextID is the id of the last request, incremented atomically
+	nextID uint64
+}
+
+// HTTPOption configures the provider returned by DialHTTP.
+type HTTPOption func(p *HTTPProvider)
+
+// DialHTTP takes a HTTP endpoint and returns a provider using it.
+func DialHTTP(endpointHTTP string, options ...HTTPOption) *HTTPProvider {
+	var httpTransport = &http.Transport{}
+	var httpClient = &http.Client{Transport: httpTransport}
+
+	p := &HTTPProvider{
+		HTTPClient:   httpClient,
+		HTTPEndpoint: endpointHTTP,
+		header:       http.Header{},
+	}
+	for _, option := range options {
+		option(p)
+	}
+
+	return p
+}
+
+// Call makes a request with a specified method and parameters.
+func (c *HTTPProvider) Call(method string, params interface{}) ([]byte, error) {
+	return c.CallContext(context.Background(), method, params)
+}
+
+// CallContext makes a request with a specified method and parameters.
+// The HTTP request is canceled when the context is done.
+//
+// Each request gets a new id, a response carrying another id fails with an *IDMismatchError.
+func (c *HTTPProvider) CallContext(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	id, dataJSON, err := c.encodeRequest(method, params)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	ctx, span := c.startSpan(ctx, method, id)
+	body, err := c.post(ctx, span, dataJSON)
+	if err == nil {
+		err = checkResponse(body, id)
+	}
+	span.end(err)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return body, nil
+}
+
+// encodeRequest returns the JSON-RPC request calling method with params, and its new id.
+func (c *HTTPProvider) encodeRequest(method string, params interface{}) (uint64, []byte, error) {
+	id := atomic.AddUint64(&c.nextID, 1)
+	dataJSON, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+		"id":      id,
+	})
+
+	return id, dataJSON, err
+}
+
+// RawCall calls a method with a JSON encoded list of params.
+//
+// method should be a string
+//    ex: "eth_getBlockByNumber"
+//
+// params is an interface
+//    ex: {"0x1", true}
+//
+func (c *HTTPProvider) RawCall(method string, args []interface{}) ([]byte, error) {
+	return c.Call(method, args)
+}
+
+// Close releases the idle connections kept by the HTTP transport.
+func (c *HTTPProvider) Close() error {
+	if t, ok := c.HTTPClient.Transport.(*http.Transport); ok {
+		t.CloseIdleConnections()
+	}
+
+	return nil
+}
+
+// post sends the encoded JSON-RPC payload and returns the response body.
+func (c *HTTPProvider) post(ctx context.Context, span *callSpan, dataJSON []byte) ([]byte, error) {
+	response, err := c.send(ctx, span, dataJSON)
+	if err != nil {
+		return []byte{}, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(c.limit(response.Body))
+	if err != nil {
+		return []byte{}, err
+	}
+	span.set(AttributeResponseSize, len(body))
+
+	// Keep the JSON-RPC errors sent along with an HTTP error status, they are more precise
+	if response.StatusCode/100 != 2 {
+		if _, ok := checkResponseError(body).(*RPCError); !ok {
+			return []byte{}, &HTTPError{
+				StatusCode: response.StatusCode,
+				Status:     response.Status,
+				Body:       body,
+			}
+		}
+	}
+
+	return body, nil
+}
+
+// send posts the encoded JSON-RPC payload and returns the response, whose body must be closed.
+func (c *HTTPProvider) send(ctx context.Context, span *callSpan, dataJSON []byte) (*http.Response, error) {
+	if c.gzip {
+		var compressed bytes.Buffer
+		w := gzip.NewWriter(&compressed)
+		if _, err := w.Write(dataJSON); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		dataJSON = compressed.Bytes()
+	}
+	span.set(AttributeRequestSize, len(dataJSON))
+
+	request, err := http.NewRequest("POST", c.HTTPEndpoint, bytes.NewReader(dataJSON))
+	if err != nil {
+		return nil, err
+	}
+	request = request.WithContext(ctx)
+	defer request.Body.Close()
+	request.Header.Add("Content-Type", "application/json")
+	if c.gzip {
+		request.Header.Set("Content-Encoding", "gzip")
+	}
+	for key, values := range c.header {
+		request.Header[key] = values
+	}
+	for key, values := range headerFromContext(ctx) {
+		request.Header[key] = values
+	}
+	if c.jwt != nil {
+		token, err := c.jwt.token()
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	span.set(AttributeHTTPStatus, response.StatusCode)
+
+	return response, nil
+}
+
+// limit fails the reads of body with ErrResponseTooLarge past the size set with WithMaxResponseSize.
+func (c *HTTPProvider) limit(body io.Reader) io.Reader {
+	if c.maxResponseSize <= 0 {
+		return body
+	}
+
+	return &limitedReader{r: body, n: c.maxResponseSize}
+}
@@ -0,0 +1,162 @@
+package provider_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cleanunicorn/ethereum/provider"
+)
+
+var _ provider.Provider = (*provider.HTTPProvider)(nil)
+
+func TestHTTPProvider_Call(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		params   interface{}
+		response string
+		wantErr  bool
+	}{
+		{
+			name:     "Result is returned untouched",
+			method:   "eth_blockNumber",
+			params:   []interface{}{},
+			response: `{"jsonrpc":"2.0","id":1,"result":"0x10"}`,
+		},
+		{
+			name:     "Node error is returned as error",
+			method:   "eth_unknown",
+			params:   []interface{}{},
+			response: `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				var req struct {
+					Method string `json:"method"`
+				}
+				if err := json.Unmarshal(body, &req); err != nil {
+					t.Errorf("Could not decode request, err: %s", err)
+				}
+				if req.Method != tt.method {
+					t.Errorf("Request method = %s, want %s", req.Method, tt.method)
+				}
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			p := provider.DialHTTP(server.URL)
+			defer p.Close()
+
+			got, err := p.Call(tt.method, tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HTTPProvider.Call() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && string(got) != tt.response {
+				t.Errorf("HTTPProvider.Call() = %s, want %s", got, tt.response)
+			}
+		})
+	}
+}
+
+func TestHTTPProvider_CallContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := provider.DialHTTP(server.URL).CallContext(ctx, "eth_blockNumber", []interface{}{})
+	if err == nil {
+		t.Fatalf("HTTPProvider.CallContext() expected error on a hung endpoint")
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("HTTPProvider.CallContext() returned before the deadline, err: %v", err)
+	}
+}
+
+func TestHTTPProvider_RequestID(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[uint64]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID uint64 `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		duplicate := seen[req.ID]
+		seen[req.ID] = true
+		mu.Unlock()
+		if duplicate {
+			t.Errorf("request id %d sent twice", req.ID)
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":"0x1"}`, req.ID)
+	}))
+	defer server.Close()
+
+	p := provider.DialHTTP(server.URL)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := p.Call("eth_blockNumber", []interface{}{}); err != nil {
+				t.Errorf("HTTPProvider.Call() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestHTTPProvider_IDMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":7,"result":"0x1"}`))
+	}))
+	defer server.Close()
+
+	_, err := provider.DialHTTP(server.URL).Call("eth_blockNumber", []interface{}{})
+	mismatch, ok := err.(*provider.IDMismatchError)
+	if !ok {
+		t.Fatalf("HTTPProvider.Call() error = %v, want *IDMismatchError", err)
+	}
+	if mismatch.Want != 1 || string(mismatch.Got) != "7" {
+		t.Errorf("IDMismatchError = %+v, want id 7 for request 1", mismatch)
+	}
+}
+
+// echoID makes a stand-in node answer with the id of the request instead of the
+// id 1 of its canned responses.
+func echoID(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		json.Unmarshal(body, &req)
+
+		recorder := httptest.NewRecorder()
+		handler(recorder, r)
+		for key, values := range recorder.Header() {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(recorder.Code)
+		w.Write(bytes.Replace(recorder.Body.Bytes(), []byte(`"id":1,`), []byte(`"id":`+string(req.ID)+`,`), 1))
+	}
+}
@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+)
+
+// IPCProvider sends JSON-RPC requests over the IPC socket of a local node.
+//
+// Concurrent calls are multiplexed on the same connection and matched to their
+// responses by request ID.
+type IPCProvider struct {
+	stream *stream
+}
+
+// DialIPC connects to the Unix domain socket of a node and returns a provider using it.
+//
+// path is the location of the socket, for example "/home/user/.ethereum/geth.ipc".
+func DialIPC(path string) (*IPCProvider, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &IPCProvider{
+		stream: newStream(&ipcConn{
+			conn:    conn,
+			decoder: json.NewDecoder(conn),
+		}),
+	}
+
+	return p, nil
+}
+
+// Call makes a request with a specified method and parameters.
+func (c *IPCProvider) Call(method string, params interface{}) ([]byte, error) {
+	return c.stream.call(context.Background(), method, params, nil)
+}
+
+// CallContext makes a request with a specified method and parameters,
+// giving up on the response when the context is done.
+func (c *IPCProvider) CallContext(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	return c.stream.call(ctx, method, params, nil)
+}
+
+// RawCall calls a method with a JSON encoded list of params.
+func (c *IPCProvider) RawCall(method string, args []interface{}) ([]byte, error) {
+	return c.stream.call(context.Background(), method, args, nil)
+}
+
+// Subscribe calls eth_subscribe with the given arguments and returns the subscription
+// delivering the notifications.
+//
+// args start with the subscription type ("newHeads", "logs", "newPendingTransactions")
+// followed by its parameters.
+func (c *IPCProvider) Subscribe(args ...interface{}) (*Subscription, error) {
+	return c.stream.subscribe(context.Background(), args)
+}
+
+// SubscribeContext is Subscribe giving up on the eth_subscribe response when the context is done.
+func (c *IPCProvider) SubscribeContext(ctx context.Context, args ...interface{}) (*Subscription, error) {
+	return c.stream.subscribe(ctx, args)
+}
+
+// Close closes the connection, failing the pending calls and ending all subscriptions.
+func (c *IPCProvider) Close() error {
+	return c.stream.close()
+}
+
+// ipcConn reads the JSON values one after the other and writes them newline delimited.
+type ipcConn struct {
+	conn    net.Conn
+	decoder *json.Decoder
+}
+
+func (c *ipcConn) readMessage() ([]byte, error) {
+	var message json.RawMessage
+	if err := c.decoder.Decode(&message); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+func (c *ipcConn) writeMessage(message []byte) error {
+	_, err := c.conn.Write(append(message, '\n'))
+	return err
+}
+
+func (c *ipcConn) close() error {
+	return c.conn.Close()
+}
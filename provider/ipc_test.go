@@ -0,0 +1,105 @@
+package provider_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cleanunicorn/ethereum/provider"
+)
+
+var _ provider.Provider = (*provider.IPCProvider)(nil)
+var _ provider.Subscriber = (*provider.IPCProvider)(nil)
+
+// startIPCNode starts a newline delimited JSON-RPC stand-in listening on a Unix socket.
+func startIPCNode(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "ipc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "geth.ipc")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					var req struct {
+						ID     uint64 `json:"id"`
+						Method string `json:"method"`
+					}
+					if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+						t.Errorf("Could not decode request, err: %s", err)
+						return
+					}
+					switch req.Method {
+					case "net_version":
+						fmt.Fprintf(conn, `{"jsonrpc":"2.0","id":%d,"result":"99"}`+"\n", req.ID)
+					case "eth_subscribe":
+						// Geth does not delimit the messages, the values are only concatenated
+						fmt.Fprintf(conn, `{"jsonrpc":"2.0","id":%d,"result":"0x1"}`, req.ID)
+						fmt.Fprint(conn, `{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0x1","result":"0xff"}}`)
+					default:
+						fmt.Fprintf(conn, `{"jsonrpc":"2.0","id":%d,"error":{"code":-32601,"message":"method not found"}}`+"\n", req.ID)
+					}
+				}
+			}(conn)
+		}
+	}()
+
+	return path, func() {
+		listener.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestIPCProvider(t *testing.T) {
+	path, stop := startIPCNode(t)
+	defer stop()
+
+	p, err := provider.DialIPC(path)
+	if err != nil {
+		t.Fatalf("DialIPC() error = %v", err)
+	}
+	defer p.Close()
+
+	got, err := p.Call("net_version", []interface{}{})
+	if err != nil {
+		t.Fatalf("IPCProvider.Call() error = %v", err)
+	}
+	if want := `{"jsonrpc":"2.0","id":1,"result":"99"}`; string(got) != want {
+		t.Errorf("IPCProvider.Call() = %s, want %s", got, want)
+	}
+
+	if _, err := p.Call("admin_unknown", []interface{}{}); err == nil {
+		t.Errorf("IPCProvider.Call() expected error for unknown method")
+	}
+
+	sub, err := p.Subscribe("newPendingTransactions")
+	if err != nil {
+		t.Fatalf("IPCProvider.Subscribe() error = %v", err)
+	}
+	select {
+	case got := <-sub.Notifications():
+		if string(got) != `"0xff"` {
+			t.Errorf("Subscription notification = %s, want %s", got, `"0xff"`)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for notification")
+	}
+}
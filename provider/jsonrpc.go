@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// checkResponseError returns the *RPCError of the JSON-RPC response, if it contains one.
+func checkResponseError(body []byte) error {
+	type responseError struct {
+		Jsonrpc string    `json:"jsonrpc"`
+		Error   *RPCError `json:"error"`
+	}
+	var respErr responseError
+	if err := json.Unmarshal(body, &respErr); err != nil {
+		return err
+	}
+	if respErr.Error != nil {
+		return respErr.Error
+	}
+
+	return nil
+}
+
+// checkResponse returns the *RPCError of the JSON-RPC response, or an *IDMismatchError
+// if it does not answer the request id.
+func checkResponse(body []byte, id uint64) error {
+	return decodeResponse(bytes.NewReader(body), nil, id)
+}
+
+// decodeResponse decodes a JSON-RPC response from r in a single pass, the result
+// going straight into result. It fails like CallContext on errors and mismatching ids.
+func decodeResponse(r io.Reader, result interface{}, id uint64) error {
+	if result == nil {
+		result = new(skipResult)
+	}
+	response := struct {
+		ID     json.RawMessage `json:"id"`
+		Error  *RPCError       `json:"error"`
+		Result interface{}     `json:"result"`
+	}{
+		Result: result,
+	}
+	if err := json.NewDecoder(r).Decode(&response); err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+
+	var got uint64
+	if err := json.Unmarshal(response.ID, &got); err != nil || got != id {
+		return &IDMismatchError{Want: id, Got: response.ID}
+	}
+
+	return nil
+}
+
+// skipResult ignores the result of the responses whose result is not wanted.
+type skipResult struct{}
+
+func (skipResult) UnmarshalJSON([]byte) error {
+	return nil
+}
@@ -0,0 +1,247 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the latency histograms.
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// MetricsConfig configures a MetricsProvider.
+type MetricsConfig struct {
+	// Namespace prefixes the names of the metrics, "ethereum" by default
+	Namespace string
+	// Buckets are the upper bounds of the latency histograms in seconds, in any order, DefaultLatencyBuckets by default
+	Buckets []float64
+}
+
+// MetricsProvider wraps a provider and measures its calls: requests and errors
+// by method, latency and calls in flight. Handler serves them to Prometheus.
+//
+//	metrics := provider.NewMetricsProvider(provider.DialHTTP(endpoint), provider.MetricsConfig{})
+//	http.Handle("/metrics", metrics.Handler())
+//	client := web3.NewClient(metrics)
+type MetricsProvider struct {
+	provider Provider
+	config   MetricsConfig
+
+	mu      sync.Mutex
+	methods map[string]*methodMetrics
+}
+
+// methodMetrics are the metrics of the calls of a method.
+type methodMetrics struct {
+	requests uint64
+	// errors counts the errors by JSON-RPC code, "transport" for the calls which got no response
+	errors   map[string]uint64
+	inFlight int64
+	// buckets counts the calls by latency bucket, the last one being +Inf
+	buckets []uint64
+	seconds float64
+}
+
+// NewMetricsProvider wraps p, measuring its calls.
+func NewMetricsProvider(p Provider, config MetricsConfig) *MetricsProvider {
+	if config.Namespace == "" {
+		config.Namespace = "ethereum"
+	}
+	if config.Buckets == nil {
+		config.Buckets = DefaultLatencyBuckets
+	}
+	// The buckets are searched and exported in ascending order
+	buckets := make([]float64, len(config.Buckets))
+	copy(buckets, config.Buckets)
+	sort.Float64s(buckets)
+	config.Buckets = buckets
+
+	return &MetricsProvider{
+		provider: p,
+		config:   config,
+		methods:  make(map[string]*methodMetrics),
+	}
+}
+
+// Call makes a request with a specified method and parameters.
+func (m *MetricsProvider) Call(method string, params interface{}) ([]byte, error) {
+	return m.CallContext(context.Background(), method, params)
+}
+
+// CallContext makes a request through the wrapped provider and measures it.
+func (m *MetricsProvider) CallContext(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	done := m.start(method)
+	reply, err := m.provider.CallContext(ctx, method, params)
+	done(err)
+
+	return reply, err
+}
+
+// CallResultContext decodes the result of the call through the wrapped provider and measures it.
+func (m *MetricsProvider) CallResultContext(ctx context.Context, result interface{}, method string, params interface{}) error {
+	done := m.start(method)
+	err := CallResult(ctx, m.provider, result, method, params)
+	done(err)
+
+	return err
+}
+
+// RawCall calls a method with a JSON encoded list of params.
+func (m *MetricsProvider) RawCall(method string, args []interface{}) ([]byte, error) {
+	return m.CallContext(context.Background(), method, args)
+}
+
+// BatchCallContext sends the batch through the wrapped provider, measuring each of its requests.
+func (m *MetricsProvider) BatchCallContext(ctx context.Context, batch []BatchElem) error {
+	done := make([]func(error), len(batch))
+	for i, elem := range batch {
+		done[i] = m.start(elem.Method)
+	}
+	err := BatchCallContext(ctx, m.provider, batch)
+	for i, elem := range batch {
+		if err != nil {
+			done[i](err)
+			continue
+		}
+		done[i](elem.Error)
+	}
+
+	return err
+}
+
+// SubscribeContext subscribes through the wrapped provider, measuring the eth_subscribe call.
+func (m *MetricsProvider) SubscribeContext(ctx context.Context, args ...interface{}) (*Subscription, error) {
+	subscriber, ok := m.provider.(Subscriber)
+	if !ok {
+		return nil, ErrSubscriptionNotSupported
+	}
+	done := m.start("eth_subscribe")
+	sub, err := subscriber.SubscribeContext(ctx, args...)
+	done(err)
+
+	return sub, err
+}
+
+// Close closes the wrapped provider.
+func (m *MetricsProvider) Close() error {
+	return m.provider.Close()
+}
+
+// Handler returns an HTTP handler serving the metrics in the Prometheus text format.
+func (m *MetricsProvider) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WriteMetrics(w)
+	})
+}
+
+// WriteMetrics writes the metrics in the Prometheus text format.
+func (m *MetricsProvider) WriteMetrics(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	methods := make([]string, 0, len(m.methods))
+	for method := range m.methods {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	b := bufio.NewWriter(w)
+	name := m.config.Namespace + "_rpc_requests_total"
+	fmt.Fprintf(b, "# HELP %s Number of JSON-RPC requests by method.\n# TYPE %s counter\n", name, name)
+	for _, method := range methods {
+		fmt.Fprintf(b, "%s{method=\"%s\"} %d\n", name, escapeLabel(method), m.methods[method].requests)
+	}
+
+	name = m.config.Namespace + "_rpc_errors_total"
+	fmt.Fprintf(b, "# HELP %s Number of failed JSON-RPC requests by method and error code.\n# TYPE %s counter\n", name, name)
+	for _, method := range methods {
+		errs := m.methods[method].errors
+		codes := make([]string, 0, len(errs))
+		for code := range errs {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			fmt.Fprintf(b, "%s{method=\"%s\",code=\"%s\"} %d\n", name, escapeLabel(method), code, errs[code])
+		}
+	}
+
+	name = m.config.Namespace + "_rpc_requests_in_flight"
+	fmt.Fprintf(b, "# HELP %s Number of JSON-RPC requests waiting for a response by method.\n# TYPE %s gauge\n", name, name)
+	for _, method := range methods {
+		fmt.Fprintf(b, "%s{method=\"%s\"} %d\n", name, escapeLabel(method), m.methods[method].inFlight)
+	}
+
+	name = m.config.Namespace + "_rpc_request_duration_seconds"
+	fmt.Fprintf(b, "# HELP %s Latency of the JSON-RPC requests by method.\n# TYPE %s histogram\n", name, name)
+	for _, method := range methods {
+		metrics := m.methods[method]
+		label := escapeLabel(method)
+		var cumulative uint64
+		for i, bound := range m.config.Buckets {
+			cumulative += metrics.buckets[i]
+			fmt.Fprintf(b, "%s_bucket{method=\"%s\",le=\"%s\"} %d\n", name, label, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(b, "%s_bucket{method=\"%s\",le=\"+Inf\"} %d\n", name, label, metrics.requests)
+		fmt.Fprintf(b, "%s_sum{method=\"%s\"} %s\n", name, label, strconv.FormatFloat(metrics.seconds, 'g', -1, 64))
+		fmt.Fprintf(b, "%s_count{method=\"%s\"} %d\n", name, label, metrics.requests)
+	}
+
+	return b.Flush()
+}
+
+// start counts a call of method in flight and returns the function recording its outcome.
+func (m *MetricsProvider) start(method string) func(err error) {
+	began := time.Now()
+
+	m.mu.Lock()
+	metrics := m.methods[method]
+	if metrics == nil {
+		metrics = &methodMetrics{
+			errors:  make(map[string]uint64),
+			buckets: make([]uint64, len(m.config.Buckets)+1),
+		}
+		m.methods[method] = metrics
+	}
+	metrics.inFlight++
+	m.mu.Unlock()
+
+	return func(err error) {
+		seconds := time.Since(began).Seconds()
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		metrics.inFlight--
+		metrics.requests++
+		metrics.seconds += seconds
+		metrics.buckets[sort.SearchFloat64s(m.config.Buckets, seconds)]++
+		if err != nil {
+			metrics.errors[errorCode(err)]++
+		}
+	}
+}
+
+// errorCode returns the JSON-RPC code of the error, "transport" if the node did not answer.
+func errorCode(err error) string {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return strconv.Itoa(rpcErr.Code)
+	}
+
+	return "transport"
+}
+
+// escapeLabel escapes a label value of the Prometheus text format.
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
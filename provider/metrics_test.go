@@ -0,0 +1,108 @@
+package provider_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cleanunicorn/ethereum/provider"
+)
+
+func TestMetricsProvider(t *testing.T) {
+	m := provider.NewMockProvider()
+	m.Expect("eth_blockNumber", []interface{}{}).Return("0x1").Times(2)
+	m.Expect("eth_sendRawTransaction", provider.AnyParams).ReturnError(-32000, "nonce too low")
+
+	metrics := provider.NewMetricsProvider(m, provider.MetricsConfig{Buckets: []float64{60}})
+	metrics.Call("eth_blockNumber", []interface{}{})
+	var number string
+	provider.CallResult(context.Background(), metrics, &number, "eth_blockNumber", []interface{}{})
+	metrics.Call("eth_sendRawTransaction", []interface{}{"0x00"})
+	// Not expected by the mock, it fails without a JSON-RPC error
+	metrics.Call("eth_gasPrice", []interface{}{})
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+
+	tests := []string{
+		`ethereum_rpc_requests_total{method="eth_blockNumber"} 2`,
+		`ethereum_rpc_requests_total{method="eth_sendRawTransaction"} 1`,
+		`ethereum_rpc_errors_total{method="eth_sendRawTransaction",code="-32000"} 1`,
+		`ethereum_rpc_errors_total{method="eth_gasPrice",code="transport"} 1`,
+		`ethereum_rpc_requests_in_flight{method="eth_blockNumber"} 0`,
+		`ethereum_rpc_request_duration_seconds_bucket{method="eth_blockNumber",le="60"} 2`,
+		`ethereum_rpc_request_duration_seconds_bucket{method="eth_blockNumber",le="+Inf"} 2`,
+		`ethereum_rpc_request_duration_seconds_count{method="eth_blockNumber"} 2`,
+		"# TYPE ethereum_rpc_request_duration_seconds histogram",
+	}
+	for _, want := range tests {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("MetricsProvider.Handler() missing %q in\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, `ethereum_rpc_errors_total{method="eth_blockNumber"`) {
+		t.Errorf("MetricsProvider.Handler() counted errors for eth_blockNumber")
+	}
+}
+
+func TestMetricsProvider_InFlight(t *testing.T) {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(echoID(func(w http.ResponseWriter, r *http.Request) {
+		close(received)
+		<-release
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer server.Close()
+
+	metrics := provider.NewMetricsProvider(provider.DialHTTP(server.URL), provider.MetricsConfig{Namespace: "node"})
+	done := make(chan struct{})
+	go func() {
+		metrics.Call("eth_blockNumber", []interface{}{})
+		close(done)
+	}()
+	<-received
+
+	metricsServer := httptest.NewServer(metrics.Handler())
+	defer metricsServer.Close()
+	response, err := http.Get(metricsServer.URL)
+	if err != nil {
+		t.Fatalf("GET metrics error = %v", err)
+	}
+	body, _ := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	close(release)
+	<-done
+
+	if want := `node_rpc_requests_in_flight{method="eth_blockNumber"} 1`; !strings.Contains(string(body), want) {
+		t.Errorf("MetricsProvider.Handler() missing %q in\n%s", want, body)
+	}
+}
+
+func TestMetricsProvider_UnsortedBuckets(t *testing.T) {
+	m := provider.NewMockProvider()
+	m.Expect("eth_blockNumber", []interface{}{}).Return("0x1")
+
+	buckets := []float64{60, 0.000001, 1}
+	metrics := provider.NewMetricsProvider(m, provider.MetricsConfig{Buckets: buckets})
+	metrics.Call("eth_blockNumber", []interface{}{})
+
+	var body strings.Builder
+	if err := metrics.WriteMetrics(&body); err != nil {
+		t.Fatalf("MetricsProvider.WriteMetrics() error = %v", err)
+	}
+	want := `ethereum_rpc_request_duration_seconds_bucket{method="eth_blockNumber",le="1e-06"} 0
+ethereum_rpc_request_duration_seconds_bucket{method="eth_blockNumber",le="1"} 1
+ethereum_rpc_request_duration_seconds_bucket{method="eth_blockNumber",le="60"} 1
+`
+	if !strings.Contains(body.String(), want) {
+		t.Errorf("MetricsProvider.WriteMetrics() buckets are not sorted in\n%s", body.String())
+	}
+	if buckets[0] != 60 {
+		t.Errorf("NewMetricsProvider() sorted the buckets of the caller")
+	}
+}
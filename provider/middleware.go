@@ -0,0 +1,251 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Caller makes a JSON-RPC call and returns the raw response, like Provider.CallContext.
+type Caller func(ctx context.Context, method string, params interface{}) ([]byte, error)
+
+// Middleware wraps a Caller to act before and after the call: log it, measure it,
+// rewrite the request or the response.
+type Middleware func(next Caller) Caller
+
+// ChainProvider sends the calls of a provider through a chain of middlewares.
+//
+// Each request of a batch goes through the chain on its own, the requests reaching
+// the end of the chain are then sent together as a single batch.
+type ChainProvider struct {
+	provider    Provider
+	middlewares []Middleware
+	call        Caller
+}
+
+// Chain wraps p with the middlewares. The first middleware is the outermost one,
+// it sees the calls first and the responses last.
+func Chain(p Provider, middlewares ...Middleware) *ChainProvider {
+	c := &ChainProvider{
+		provider:    p,
+		middlewares: middlewares,
+	}
+	c.call = c.build(p.CallContext)
+
+	return c
+}
+
+// build returns the chain of middlewares ending with last.
+func (c *ChainProvider) build(last Caller) Caller {
+	call := last
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		call = c.middlewares[i](call)
+	}
+
+	return call
+}
+
+// Call makes a request with a specified method and parameters.
+func (c *ChainProvider) Call(method string, params interface{}) ([]byte, error) {
+	return c.call(context.Background(), method, params)
+}
+
+// CallContext makes a request through the middlewares.
+func (c *ChainProvider) CallContext(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	return c.call(ctx, method, params)
+}
+
+// CallResultContext decodes the result of the call into result. The middlewares work on
+// the raw response, so the result is decoded as it is received only without middlewares.
+func (c *ChainProvider) CallResultContext(ctx context.Context, result interface{}, method string, params interface{}) error {
+	if len(c.middlewares) == 0 {
+		return CallResult(ctx, c.provider, result, method, params)
+	}
+
+	reply, err := c.call(ctx, method, params)
+	if err != nil {
+		return err
+	}
+
+	return unmarshalResult(reply, result)
+}
+
+// RawCall calls a method with a JSON encoded list of params.
+func (c *ChainProvider) RawCall(method string, args []interface{}) ([]byte, error) {
+	return c.call(context.Background(), method, args)
+}
+
+// BatchCallContext passes each request of the batch through the middlewares, then sends
+// the requests reaching the end of the chain in a single batch through the wrapped provider.
+//
+// The batch carries the headers the middlewares added to the context of its requests.
+// A request a middleware sends again after the batch goes alone.
+func (c *ChainProvider) BatchCallContext(ctx context.Context, batch []BatchElem) error {
+	if len(c.middlewares) == 0 {
+		return BatchCallContext(ctx, c.provider, batch)
+	}
+
+	b := &chainBatch{
+		provider:  c.provider,
+		ctx:       ctx,
+		arrived:   make([]bool, len(batch)),
+		remaining: len(batch),
+		sent:      make(chan struct{}),
+	}
+	call := c.build(b.call)
+
+	var wg sync.WaitGroup
+	for i := range batch {
+		wg.Add(1)
+		go func(i int, elem *BatchElem) {
+			defer wg.Done()
+			elem.Result, elem.Error = call(context.WithValue(ctx, chainBatchKey{}, i), elem.Method, elem.Params)
+			b.leave(i)
+		}(i, &batch[i])
+	}
+	wg.Wait()
+
+	return b.err
+}
+
+type chainBatchKey struct{}
+
+// chainBatch gathers the requests of a batch reaching the end of the chain, the batch is
+// sent once every request either reached it or returned before.
+type chainBatch struct {
+	provider Provider
+	ctx      context.Context
+
+	mu        sync.Mutex
+	requests  []BatchElem
+	arrived   []bool
+	remaining int
+	sent      chan struct{}
+	err       error
+}
+
+// call is the end of the chain for the requests of the batch.
+func (b *chainBatch) call(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	i, ok := ctx.Value(chainBatchKey{}).(int)
+	b.mu.Lock()
+	if !ok || b.arrived[i] {
+		b.mu.Unlock()
+		return b.provider.CallContext(ctx, method, params)
+	}
+	b.arrived[i] = true
+	b.remaining--
+	n := len(b.requests)
+	b.requests = append(b.requests, BatchElem{Method: method, Params: params})
+	if header := headerFromContext(ctx); header != nil {
+		b.ctx = ContextWithHeader(b.ctx, header)
+	}
+	last := b.remaining == 0
+	b.mu.Unlock()
+
+	if last {
+		b.send()
+	}
+	<-b.sent
+	if b.err != nil {
+		return []byte{}, b.err
+	}
+
+	return b.requests[n].Result, b.requests[n].Error
+}
+
+// leave records that the request i returned, sending the batch if it was the last one
+// the batch waited for.
+func (b *chainBatch) leave(i int) {
+	b.mu.Lock()
+	if b.arrived[i] {
+		b.mu.Unlock()
+		return
+	}
+	b.arrived[i] = true
+	b.remaining--
+	last := b.remaining == 0 && len(b.requests) > 0
+	b.mu.Unlock()
+
+	if last {
+		b.send()
+	}
+}
+
+// send sends the gathered requests through the wrapped provider.
+func (b *chainBatch) send() {
+	b.err = BatchCallContext(b.ctx, b.provider, b.requests)
+	close(b.sent)
+}
+
+// SubscribeContext subscribes through the wrapped provider, bypassing the middlewares.
+func (c *ChainProvider) SubscribeContext(ctx context.Context, args ...interface{}) (*Subscription, error) {
+	subscriber, ok := c.provider.(Subscriber)
+	if !ok {
+		return nil, ErrSubscriptionNotSupported
+	}
+
+	return subscriber.SubscribeContext(ctx, args...)
+}
+
+// Close closes the wrapped provider.
+func (c *ChainProvider) Close() error {
+	return c.provider.Close()
+}
+
+// LoggingMiddleware logs every call with logrus: successful calls at debug level,
+// failed calls at warning level.
+func LoggingMiddleware() Middleware {
+	return func(next Caller) Caller {
+		return func(ctx context.Context, method string, params interface{}) ([]byte, error) {
+			start := time.Now()
+			reply, err := next(ctx, method, params)
+
+			entry := log.WithFields(log.Fields{
+				"method":   method,
+				"duration": time.Since(start),
+			})
+			if err != nil {
+				entry.WithError(err).Warn("RPC call failed")
+			} else {
+				entry.WithField("size", len(reply)).Debug("RPC call")
+			}
+
+			return reply, err
+		}
+	}
+}
+
+type headerKey struct{}
+
+// ContextWithHeader returns a context adding the header to the HTTP requests made with it.
+func ContextWithHeader(ctx context.Context, header http.Header) context.Context {
+	merged := http.Header{}
+	if previous, ok := ctx.Value(headerKey{}).(http.Header); ok {
+		for key, values := range previous {
+			merged[key] = append([]string{}, values...)
+		}
+	}
+	for key, values := range header {
+		merged[http.CanonicalHeaderKey(key)] = append([]string{}, values...)
+	}
+
+	return context.WithValue(ctx, headerKey{}, merged)
+}
+
+// headerFromContext returns the header added to the context by ContextWithHeader.
+func headerFromContext(ctx context.Context) http.Header {
+	header, _ := ctx.Value(headerKey{}).(http.Header)
+	return header
+}
+
+// HeaderMiddleware adds the header to the HTTP requests of the calls.
+func HeaderMiddleware(header http.Header) Middleware {
+	return func(next Caller) Caller {
+		return func(ctx context.Context, method string, params interface{}) ([]byte, error) {
+			return next(ContextWithHeader(ctx, header), method, params)
+		}
+	}
+}
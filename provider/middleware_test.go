@@ -0,0 +1,178 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cleanunicorn/ethereum/provider"
+)
+
+func TestChain(t *testing.T) {
+	var gotMethod, gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req struct {
+			Method string `json:"method"`
+		}
+		json.Unmarshal(body, &req)
+		gotMethod = req.Method
+		gotHeader = r.Header.Get("X-Api-Key")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer server.Close()
+
+	var order []string
+	trace := func(name string) provider.Middleware {
+		return func(next provider.Caller) provider.Caller {
+			return func(ctx context.Context, method string, params interface{}) ([]byte, error) {
+				order = append(order, name+" before")
+				reply, err := next(ctx, method, params)
+				order = append(order, name+" after")
+				return reply, err
+			}
+		}
+	}
+	rewrite := func(next provider.Caller) provider.Caller {
+		return func(ctx context.Context, method string, params interface{}) ([]byte, error) {
+			if method == "eth_blockNumberAlias" {
+				method = "eth_blockNumber"
+			}
+			return next(ctx, method, params)
+		}
+	}
+
+	p := provider.Chain(provider.DialHTTP(server.URL),
+		provider.LoggingMiddleware(),
+		trace("outer"),
+		trace("inner"),
+		provider.HeaderMiddleware(http.Header{"X-Api-Key": {"secret"}}),
+		rewrite,
+	)
+	if _, err := p.Call("eth_blockNumberAlias", []interface{}{}); err != nil {
+		t.Fatalf("ChainProvider.Call() error = %v", err)
+	}
+
+	if want := []string{"outer before", "inner before", "inner after", "outer after"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("Middleware order = %v, want %v", order, want)
+	}
+	if gotMethod != "eth_blockNumber" {
+		t.Errorf("Node received method %s, want eth_blockNumber", gotMethod)
+	}
+	if gotHeader != "secret" {
+		t.Errorf("Node received X-Api-Key %q, want secret", gotHeader)
+	}
+}
+
+func TestChain_Batch(t *testing.T) {
+	var requests int32
+	var gotMethods []string
+	var gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		gotHeader = r.Header.Get("X-Api-Key")
+		var batch []struct {
+			ID     uint64 `json:"id"`
+			Method string `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			t.Errorf("Node received a request which is not a batch, err: %s", err)
+			return
+		}
+		replies := make([]string, len(batch))
+		for i, req := range batch {
+			gotMethods = append(gotMethods, req.Method)
+			replies[i] = fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"0x%x"}`, req.ID, i+1)
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(replies, ","))
+	}))
+	defer server.Close()
+
+	var seen int32
+	count := func(next provider.Caller) provider.Caller {
+		return func(ctx context.Context, method string, params interface{}) ([]byte, error) {
+			atomic.AddInt32(&seen, 1)
+			return next(ctx, method, params)
+		}
+	}
+	cached := func(next provider.Caller) provider.Caller {
+		return func(ctx context.Context, method string, params interface{}) ([]byte, error) {
+			if method == "eth_chainId" {
+				return []byte(`"0x2a"`), nil
+			}
+			return next(ctx, method, params)
+		}
+	}
+
+	p := provider.Chain(provider.DialHTTP(server.URL),
+		count,
+		provider.HeaderMiddleware(http.Header{"X-Api-Key": {"secret"}}),
+		cached,
+	)
+	batch := []provider.BatchElem{
+		{Method: "eth_blockNumber", Params: []interface{}{}},
+		{Method: "eth_chainId", Params: []interface{}{}},
+		{Method: "eth_gasPrice", Params: []interface{}{}},
+	}
+	if err := provider.BatchCallContext(context.Background(), p, batch); err != nil {
+		t.Fatalf("ChainProvider.BatchCallContext() error = %v", err)
+	}
+
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("Node received %d requests, want a single batch", got)
+	}
+	if got := atomic.LoadInt32(&seen); got != int32(len(batch)) {
+		t.Errorf("Middleware saw %d requests, want %d", got, len(batch))
+	}
+	if len(gotMethods) != 2 {
+		t.Errorf("Node received methods %v, want eth_blockNumber and eth_gasPrice", gotMethods)
+	}
+	if gotHeader != "secret" {
+		t.Errorf("Node received X-Api-Key %q, want secret", gotHeader)
+	}
+	for i, elem := range batch {
+		if elem.Error != nil || len(elem.Result) == 0 {
+			t.Errorf("Batch element %d = %s, %v, want a result", i, elem.Result, elem.Error)
+		}
+	}
+	if got := string(batch[1].Result); got != `"0x2a"` {
+		t.Errorf("Batch element 1 = %s, want the middleware result", got)
+	}
+}
+
+func TestChain_CallResult(t *testing.T) {
+	tests := []struct {
+		name        string
+		middlewares []provider.Middleware
+	}{
+		{
+			name: "Without middlewares",
+		},
+		{
+			name:        "With middlewares",
+			middlewares: []provider.Middleware{provider.LoggingMiddleware()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := provider.NewMockProvider()
+			m.Expect("eth_blockNumber", []interface{}{}).Return("0x10")
+
+			var got string
+			err := provider.CallResult(context.Background(), provider.Chain(m, tt.middlewares...), &got, "eth_blockNumber", []interface{}{})
+			if err != nil || got != "0x10" {
+				t.Errorf("CallResult() = %q, %v, want 0x10", got, err)
+			}
+			if err := m.Verify(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
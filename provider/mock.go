@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// AnyParams matches the params of any call when given to MockProvider.Expect.
+var AnyParams = anyParams{}
+
+type anyParams struct{}
+
+// MockProvider answers the calls of unit tests with the results of expectations
+// registered beforehand, without a node.
+//
+//	m := provider.NewMockProvider()
+//	m.Expect("eth_getBalance", []interface{}{address, "latest"}).Return("0x10")
+//	balance, err := web3.NewClient(m).Eth.GetBalance(address, "latest")
+//	if err := m.Verify(); err != nil {
+//		t.Error(err)
+//	}
+type MockProvider struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	ordered      bool
+	unexpected   []string
+}
+
+// Expectation is a call expected by a MockProvider and its answer.
+type Expectation struct {
+	method string
+	// params is the JSON encoded params, nil for AnyParams
+	params json.RawMessage
+	result json.RawMessage
+	err    *RPCError
+	times  int
+	calls  int
+}
+
+// NewMockProvider returns a mock without expectations.
+func NewMockProvider() *MockProvider {
+	return &MockProvider{}
+}
+
+// InOrder makes the calls fail unless they are made in the order of the expectations.
+func (m *MockProvider) InOrder() *MockProvider {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ordered = true
+	return m
+}
+
+// Expect registers a call of method with params, compared once encoded to JSON.
+// The expectation answers a single call with a null result until Return, ReturnError
+// or Times is used.
+func (m *MockProvider) Expect(method string, params interface{}) *Expectation {
+	e := &Expectation{
+		method: method,
+		result: json.RawMessage("null"),
+		times:  1,
+	}
+	if _, ok := params.(anyParams); !ok {
+		paramsJSON, err := json.Marshal(params)
+		if err != nil {
+			panic(fmt.Sprintf("mock: can not encode the params of %s: %s", method, err))
+		}
+		e.params = compactJSON(paramsJSON)
+	}
+
+	m.mu.Lock()
+	m.expectations = append(m.expectations, e)
+	m.mu.Unlock()
+
+	return e
+}
+
+// Return sets the result of the expected call, encoded to JSON. A json.RawMessage is used as is.
+func (e *Expectation) Return(result interface{}) *Expectation {
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		panic(fmt.Sprintf("mock: can not encode the result of %s: %s", e.method, err))
+	}
+	e.result = resultJSON
+	return e
+}
+
+// ReturnError makes the expected call fail with a JSON-RPC error.
+func (e *Expectation) ReturnError(code int, message string) *Expectation {
+	e.err = &RPCError{
+		Code:    code,
+		Message: message,
+	}
+	return e
+}
+
+// Times sets how many calls the expectation answers, once by default.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+func (e *Expectation) String() string {
+	if e.params == nil {
+		return e.method + " with any params"
+	}
+	return fmt.Sprintf("%s with params %s", e.method, e.params)
+}
+
+func (e *Expectation) matches(method string, paramsJSON []byte) bool {
+	return e.method == method && (e.params == nil || string(e.params) == string(paramsJSON))
+}
+
+// Call makes a request with a specified method and parameters.
+func (m *MockProvider) Call(method string, params interface{}) ([]byte, error) {
+	return m.CallContext(context.Background(), method, params)
+}
+
+// CallContext answers the call with the first expectation matching it.
+func (m *MockProvider) CallContext(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return []byte{}, err
+	}
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return []byte{}, err
+	}
+	paramsJSON = compactJSON(paramsJSON)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var found *Expectation
+	for _, e := range m.expectations {
+		if e.calls >= e.times {
+			continue
+		}
+		if e.matches(method, paramsJSON) {
+			found = e
+			break
+		}
+		if m.ordered {
+			err := fmt.Errorf("mock: call of %s with params %s out of order, expected %s", method, paramsJSON, e)
+			m.unexpected = append(m.unexpected, err.Error())
+			return []byte{}, err
+		}
+	}
+	if found == nil {
+		err := fmt.Errorf("mock: unexpected call of %s with params %s", method, paramsJSON)
+		m.unexpected = append(m.unexpected, err.Error())
+		return []byte{}, err
+	}
+
+	found.calls++
+	if found.err != nil {
+		return []byte{}, found.err
+	}
+
+	return []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"result":%s}`, found.result)), nil
+}
+
+// RawCall calls a method with a JSON encoded list of params.
+func (m *MockProvider) RawCall(method string, args []interface{}) ([]byte, error) {
+	return m.CallContext(context.Background(), method, args)
+}
+
+// Close does nothing, there is no connection to release.
+func (m *MockProvider) Close() error {
+	return nil
+}
+
+// Verify returns an error listing the expected calls which were not made and the
+// calls which were not expected.
+func (m *MockProvider) Verify() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	problems := append([]string{}, m.unexpected...)
+	for _, e := range m.expectations {
+		if e.calls < e.times {
+			problems = append(problems, fmt.Sprintf("mock: expected call of %s, made %d of %d times", e, e.calls, e.times))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return errors.New(strings.Join(problems, "\n"))
+}
+
+// compactJSON removes the insignificant spaces of valid JSON.
+func compactJSON(data []byte) json.RawMessage {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return data
+	}
+	return compact.Bytes()
+}
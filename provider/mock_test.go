@@ -0,0 +1,84 @@
+package provider_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/cleanunicorn/ethereum/provider"
+	"github.com/cleanunicorn/ethereum/web3/eth"
+)
+
+const mockAccount = "0x00000000000000000000000000000000000000ff"
+
+func TestMockProvider_Eth(t *testing.T) {
+	m := provider.NewMockProvider()
+	m.Expect("eth_getBalance", []interface{}{mockAccount, "latest"}).Return("0x10")
+	m.Expect("eth_sendRawTransaction", provider.AnyParams).ReturnError(-32000, "nonce too low")
+
+	e := eth.NewEth(m)
+	balance, err := e.GetBalance(mockAccount, "latest")
+	if err != nil || balance.Cmp(big.NewInt(16)) != 0 {
+		t.Errorf("GetBalance() = %v, %v, want 16", balance, err)
+	}
+	if _, err := e.SendRawTransaction("0x00"); !errors.Is(err, provider.ErrNonceTooLow) {
+		t.Errorf("SendRawTransaction() error = %v, want %v", err, provider.ErrNonceTooLow)
+	}
+
+	if err := m.Verify(); err != nil {
+		t.Errorf("MockProvider.Verify() error = %v", err)
+	}
+}
+
+func TestMockProvider_Verify(t *testing.T) {
+	tests := []struct {
+		name    string
+		ordered bool
+		calls   []string
+		wantErr bool
+	}{
+		{
+			name:  "All expectations met",
+			calls: []string{"eth_blockNumber", "net_version", "eth_blockNumber"},
+		},
+		{
+			name:    "Missing call",
+			calls:   []string{"eth_blockNumber", "net_version"},
+			wantErr: true,
+		},
+		{
+			name:    "Unexpected call",
+			calls:   []string{"eth_blockNumber", "net_version", "eth_blockNumber", "eth_gasPrice"},
+			wantErr: true,
+		},
+		{
+			name:    "In order",
+			ordered: true,
+			calls:   []string{"eth_blockNumber", "eth_blockNumber", "net_version"},
+		},
+		{
+			name:    "Out of order",
+			ordered: true,
+			calls:   []string{"net_version", "eth_blockNumber", "eth_blockNumber"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := provider.NewMockProvider()
+			if tt.ordered {
+				m.InOrder()
+			}
+			m.Expect("eth_blockNumber", []interface{}{}).Return("0x1").Times(2)
+			m.Expect("net_version", []interface{}{}).Return("1")
+
+			for _, method := range tt.calls {
+				m.Call(method, []interface{}{})
+			}
+
+			if err := m.Verify(); (err != nil) != tt.wantErr {
+				t.Errorf("MockProvider.Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
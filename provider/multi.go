@@ -0,0 +1,286 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// MultiMode selects how a MultiProvider spreads the calls over its providers.
+type MultiMode int
+
+const (
+	// Failover sends the calls to the first provider, trying the next ones when it fails
+	Failover MultiMode = iota
+	// RoundRobin sends each call to the next provider, trying the following ones when it fails
+	RoundRobin
+	// Quorum sends each call to several providers and requires enough of them to agree
+	Quorum
+)
+
+// ErrNoProviders is returned by a MultiProvider created without providers.
+var ErrNoProviders = errors.New("provider: no providers")
+
+// QuorumError is returned when not enough providers agreed on the result of a call.
+type QuorumError struct {
+	Method    string
+	Threshold int
+	// Votes is the number of providers agreeing on the most common answer
+	Votes int
+	// Errors are the errors returned by the providers that failed
+	Errors []error
+}
+
+func (e *QuorumError) Error() string {
+	return fmt.Sprintf("no quorum for %s: %d of %d required providers agree, %d failed", e.Method, e.Votes, e.Threshold, len(e.Errors))
+}
+
+// MultiConfig configures a MultiProvider.
+type MultiConfig struct {
+	Mode MultiMode
+	// QuorumSize is the number of providers queried by each call in Quorum mode, all of them by default
+	QuorumSize int
+	// QuorumThreshold is the number of matching results required in Quorum mode, a majority of QuorumSize by default
+	QuorumThreshold int
+}
+
+// MultiProvider sends the calls to several providers, usually one per node, to survive
+// an unavailable, lagging or lying node.
+type MultiProvider struct {
+	providers []Provider
+	config    MultiConfig
+	next      uint32
+}
+
+// NewMultiProvider returns a provider spreading the calls over providers as configured.
+func NewMultiProvider(config MultiConfig, providers ...Provider) *MultiProvider {
+	if config.QuorumSize <= 0 || config.QuorumSize > len(providers) {
+		config.QuorumSize = len(providers)
+	}
+	if config.QuorumThreshold <= 0 || config.QuorumThreshold > config.QuorumSize {
+		config.QuorumThreshold = config.QuorumSize/2 + 1
+	}
+
+	return &MultiProvider{
+		providers: providers,
+		config:    config,
+	}
+}
+
+// Call makes a request with a specified method and parameters.
+func (m *MultiProvider) Call(method string, params interface{}) ([]byte, error) {
+	return m.CallContext(context.Background(), method, params)
+}
+
+// CallContext makes a request with a specified method and parameters, through the
+// providers selected by the mode.
+func (m *MultiProvider) CallContext(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	if len(m.providers) == 0 {
+		return []byte{}, ErrNoProviders
+	}
+
+	if m.config.Mode == Quorum {
+		return m.quorum(ctx, method, params)
+	}
+
+	var reply []byte
+	err := m.each(ctx, func(p Provider) error {
+		var err error
+		reply, err = p.CallContext(ctx, method, params)
+		return err
+	})
+
+	return reply, err
+}
+
+// CallResultContext decodes the result of the call into result. In Quorum mode the
+// providers vote on their raw responses, the winning one is decoded.
+func (m *MultiProvider) CallResultContext(ctx context.Context, result interface{}, method string, params interface{}) error {
+	if len(m.providers) == 0 {
+		return ErrNoProviders
+	}
+
+	if m.config.Mode == Quorum {
+		reply, err := m.quorum(ctx, method, params)
+		if err != nil {
+			return err
+		}
+		return unmarshalResult(reply, result)
+	}
+
+	return m.each(ctx, func(p Provider) error {
+		return CallResult(ctx, p, result, method, params)
+	})
+}
+
+// RawCall calls a method with a JSON encoded list of params.
+func (m *MultiProvider) RawCall(method string, args []interface{}) ([]byte, error) {
+	return m.CallContext(context.Background(), method, args)
+}
+
+// BatchCallContext sends the batch to one provider, trying the next ones if the
+// whole batch fails. In Quorum mode every request is voted on separately.
+func (m *MultiProvider) BatchCallContext(ctx context.Context, batch []BatchElem) error {
+	if len(m.providers) == 0 {
+		return ErrNoProviders
+	}
+
+	if m.config.Mode == Quorum {
+		var wg sync.WaitGroup
+		for i := range batch {
+			wg.Add(1)
+			go func(elem *BatchElem) {
+				defer wg.Done()
+				elem.Result, elem.Error = m.quorum(ctx, elem.Method, elem.Params)
+			}(&batch[i])
+		}
+		wg.Wait()
+
+		return nil
+	}
+
+	return m.each(ctx, func(p Provider) error {
+		return BatchCallContext(ctx, p, batch)
+	})
+}
+
+// SubscribeContext subscribes through the first provider supporting subscriptions
+// which accepts it.
+func (m *MultiProvider) SubscribeContext(ctx context.Context, args ...interface{}) (*Subscription, error) {
+	err := ErrSubscriptionNotSupported
+	for _, p := range m.providers {
+		subscriber, ok := p.(Subscriber)
+		if !ok {
+			continue
+		}
+
+		var sub *Subscription
+		sub, err = subscriber.SubscribeContext(ctx, args...)
+		if err == nil {
+			return sub, nil
+		}
+	}
+
+	return nil, err
+}
+
+// Close closes all the providers and returns the first error.
+func (m *MultiProvider) Close() error {
+	var err error
+	for _, p := range m.providers {
+		if closeErr := p.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// each runs call on the providers, in the order given by the mode, until one succeeds.
+//
+// Errors returned by the node are final, the next provider would answer the same.
+func (m *MultiProvider) each(ctx context.Context, call func(p Provider) error) error {
+	start := 0
+	if m.config.Mode == RoundRobin {
+		start = int((atomic.AddUint32(&m.next, 1) - 1) % uint32(len(m.providers)))
+	}
+
+	var err error
+	for i := 0; i < len(m.providers); i++ {
+		err = call(m.providers[(start+i)%len(m.providers)])
+		if err == nil {
+			return nil
+		}
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) || ctx.Err() != nil {
+			return err
+		}
+		log.Debugf("Provider %d failed, trying the next one, err: %s", (start+i)%len(m.providers), err)
+	}
+
+	return err
+}
+
+// quorum sends the call to QuorumSize providers and returns the answer shared by at
+// least QuorumThreshold of them. Identical node errors count as an answer.
+func (m *MultiProvider) quorum(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	type answer struct {
+		reply []byte
+		err   error
+		key   string
+	}
+	answers := make([]answer, m.config.QuorumSize)
+
+	var wg sync.WaitGroup
+	for i := 0; i < m.config.QuorumSize; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reply, err := m.providers[i].CallContext(ctx, method, params)
+			answers[i] = answer{
+				reply: reply,
+				err:   err,
+				key:   quorumKey(reply, err),
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	votes := make(map[string]int)
+	best := -1
+	var errs []error
+	for i, a := range answers {
+		if a.key == "" {
+			errs = append(errs, a.err)
+			continue
+		}
+		votes[a.key]++
+		if best == -1 || votes[a.key] > votes[answers[best].key] {
+			best = i
+		}
+	}
+
+	if best == -1 || votes[answers[best].key] < m.config.QuorumThreshold {
+		qErr := &QuorumError{
+			Method:    method,
+			Threshold: m.config.QuorumThreshold,
+			Errors:    errs,
+		}
+		if best != -1 {
+			qErr.Votes = votes[answers[best].key]
+		}
+		return []byte{}, qErr
+	}
+
+	return answers[best].reply, answers[best].err
+}
+
+// quorumKey identifies an answer, two providers agree if they return the same key.
+// Only results and node errors can be compared, other failures return an empty key.
+func quorumKey(reply []byte, err error) string {
+	if err != nil {
+		if rpcErr, ok := err.(*RPCError); ok {
+			return "error:" + rpcErr.Error()
+		}
+		return ""
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(reply, &response); err != nil {
+		return ""
+	}
+	var result bytes.Buffer
+	if err := json.Compact(&result, response.Result); err != nil {
+		return ""
+	}
+
+	return "result:" + result.String()
+}
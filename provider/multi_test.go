@@ -0,0 +1,153 @@
+package provider_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cleanunicorn/ethereum/provider"
+)
+
+// startNodes starts one stand-in node per response, an empty response makes the node fail.
+func startNodes(t *testing.T, responses ...string) ([]provider.Provider, []int, func()) {
+	var providers []provider.Provider
+	var servers []*httptest.Server
+	calls := make([]int, len(responses))
+	for i, response := range responses {
+		i, response := i, response
+		server := httptest.NewServer(echoID(func(w http.ResponseWriter, r *http.Request) {
+			calls[i]++
+			if response == "" {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.Write([]byte(response))
+		}))
+		servers = append(servers, server)
+		providers = append(providers, provider.DialHTTP(server.URL))
+	}
+
+	return providers, calls, func() {
+		for _, server := range servers {
+			server.Close()
+		}
+	}
+}
+
+func result(t *testing.T, reply []byte) string {
+	var response struct {
+		Result string `json:"result"`
+	}
+	if err := json.Unmarshal(reply, &response); err != nil {
+		t.Errorf("Could not decode reply %s, err: %s", reply, err)
+	}
+	return response.Result
+}
+
+func TestMultiProvider_Failover(t *testing.T) {
+	providers, calls, stop := startNodes(t,
+		"",
+		`{"jsonrpc":"2.0","id":1,"result":"0x2"}`,
+		`{"jsonrpc":"2.0","id":1,"result":"0x3"}`,
+	)
+	defer stop()
+
+	m := provider.NewMultiProvider(provider.MultiConfig{Mode: provider.Failover}, providers...)
+	for i := 0; i < 2; i++ {
+		reply, err := m.Call("eth_blockNumber", []interface{}{})
+		if err != nil {
+			t.Fatalf("MultiProvider.Call() error = %v", err)
+		}
+		if got := result(t, reply); got != "0x2" {
+			t.Errorf("MultiProvider.Call() = %s, want 0x2", got)
+		}
+	}
+	if calls[0] != 2 || calls[1] != 2 || calls[2] != 0 {
+		t.Errorf("Calls per node = %v, want [2 2 0]", calls)
+	}
+}
+
+func TestMultiProvider_RoundRobin(t *testing.T) {
+	providers, calls, stop := startNodes(t,
+		`{"jsonrpc":"2.0","id":1,"result":"0x1"}`,
+		`{"jsonrpc":"2.0","id":1,"result":"0x2"}`,
+		`{"jsonrpc":"2.0","id":1,"result":"0x3"}`,
+	)
+	defer stop()
+
+	m := provider.NewMultiProvider(provider.MultiConfig{Mode: provider.RoundRobin}, providers...)
+	for i := 0; i < 6; i++ {
+		if _, err := m.Call("eth_blockNumber", []interface{}{}); err != nil {
+			t.Fatalf("MultiProvider.Call() error = %v", err)
+		}
+	}
+	for i, n := range calls {
+		if n != 2 {
+			t.Errorf("Node %d received %d calls, want 2", i, n)
+		}
+	}
+}
+
+func TestMultiProvider_Quorum(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []string
+		threshold int
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "Majority agrees despite a lying node",
+			responses: []string{
+				`{"jsonrpc":"2.0","id":1,"result":"0x64"}`,
+				`{"jsonrpc":"2.0","id":1,"result":"0x0"}`,
+				`{"jsonrpc":"2.0","id":1, "result": "0x64"}`,
+			},
+			want: "0x64",
+		},
+		{
+			name: "No majority",
+			responses: []string{
+				`{"jsonrpc":"2.0","id":1,"result":"0x1"}`,
+				`{"jsonrpc":"2.0","id":1,"result":"0x2"}`,
+				"",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Threshold of all nodes",
+			responses: []string{
+				`{"jsonrpc":"2.0","id":1,"result":"0x1"}`,
+				`{"jsonrpc":"2.0","id":1,"result":"0x1"}`,
+				`{"jsonrpc":"2.0","id":1,"result":"0x2"}`,
+			},
+			threshold: 3,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			providers, _, stop := startNodes(t, tt.responses...)
+			defer stop()
+
+			m := provider.NewMultiProvider(provider.MultiConfig{
+				Mode:            provider.Quorum,
+				QuorumThreshold: tt.threshold,
+			}, providers...)
+			reply, err := m.Call("eth_getBalance", []interface{}{"0x0", "latest"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MultiProvider.Call() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if _, ok := err.(*provider.QuorumError); !ok {
+					t.Errorf("MultiProvider.Call() error = %T, want *provider.QuorumError", err)
+				}
+				return
+			}
+			if got := result(t, reply); got != tt.want {
+				t.Errorf("MultiProvider.Call() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
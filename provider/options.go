@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrResponseTooLarge is returned when a response exceeds the size set with WithMaxResponseSize.
+var ErrResponseTooLarge = errors.New("provider: response too large")
+
+// WithHTTPClient replaces the HTTP client of the provider.
+// The options given after it configure a copy of the client and of its transport, if it
+// is an *http.Transport, the client is left as is for its other users.
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(p *HTTPProvider) {
+		copied := *client
+		p.HTTPClient = &copied
+		p.sharedTransport = true
+	}
+}
+
+// WithTimeout limits the duration of each request, including reading the response.
+func WithTimeout(timeout time.Duration) HTTPOption {
+	return func(p *HTTPProvider) {
+		p.HTTPClient.Timeout = timeout
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used to connect to https endpoints.
+func WithTLSConfig(config *tls.Config) HTTPOption {
+	return withTransport(func(t *http.Transport) {
+		t.TLSClientConfig = config
+	})
+}
+
+// WithClientCertificate presents the certificate to the endpoint, for mutual TLS.
+// Load it with tls.LoadX509KeyPair.
+func WithClientCertificate(certificate tls.Certificate) HTTPOption {
+	return withTransport(func(t *http.Transport) {
+		tlsConfig(t).Certificates = append(tlsConfig(t).Certificates, certificate)
+	})
+}
+
+// WithRootCAs verifies the certificate of the endpoint against pool instead of the
+// system roots. Load a CA bundle with LoadCABundle.
+func WithRootCAs(pool *x509.CertPool) HTTPOption {
+	return withTransport(func(t *http.Transport) {
+		tlsConfig(t).RootCAs = pool
+	})
+}
+
+// LoadCABundle reads the PEM encoded certificates of a CA bundle file.
+func LoadCABundle(path string) (*x509.CertPool, error) {
+	bundle, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("no certificate found in %s", path)
+	}
+
+	return pool, nil
+}
+
+// WithProxy sends the requests through the HTTP proxy.
+func WithProxy(proxy *url.URL) HTTPOption {
+	return withTransport(func(t *http.Transport) {
+		t.Proxy = http.ProxyURL(proxy)
+	})
+}
+
+// WithProxyFromEnvironment uses the proxy set by the HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables.
+func WithProxyFromEnvironment() HTTPOption {
+	return withTransport(func(t *http.Transport) {
+		t.Proxy = http.ProxyFromEnvironment
+	})
+}
+
+// WithMaxIdleConns sets how many idle connections are kept open to the endpoint.
+func WithMaxIdleConns(n int) HTTPOption {
+	return withTransport(func(t *http.Transport) {
+		t.MaxIdleConns = n
+		t.MaxIdleConnsPerHost = n
+	})
+}
+
+// WithGzip compresses the requests, the endpoint must accept gzip encoded requests.
+// Compressed responses are asked for and decoded by the default transport.
+func WithGzip() HTTPOption {
+	return func(p *HTTPProvider) {
+		p.gzip = true
+	}
+}
+
+// WithMaxResponseSize fails the calls whose response is larger than size bytes
+// with ErrResponseTooLarge.
+func WithMaxResponseSize(size int64) HTTPOption {
+	return func(p *HTTPProvider) {
+		p.maxResponseSize = size
+	}
+}
+
+// withTransport returns an option configuring the transport of the provider,
+// ignored if the client does not use an *http.Transport.
+func withTransport(configure func(t *http.Transport)) HTTPOption {
+	return func(p *HTTPProvider) {
+		t, ok := p.HTTPClient.Transport.(*http.Transport)
+		if !ok {
+			return
+		}
+		if p.sharedTransport {
+			t = t.Clone()
+			p.HTTPClient.Transport = t
+			p.sharedTransport = false
+		}
+		configure(t)
+	}
+}
+
+// tlsConfig returns a copy of the TLS configuration of the transport to modify, the
+// configuration may be shared with the caller of WithTLSConfig.
+func tlsConfig(t *http.Transport) *tls.Config {
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	} else {
+		t.TLSClientConfig = t.TLSClientConfig.Clone()
+	}
+
+	return t.TLSClientConfig
+}
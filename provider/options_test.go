@@ -0,0 +1,167 @@
+package provider_test
+
+import (
+	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cleanunicorn/ethereum/provider"
+)
+
+func TestDialHTTP_Gzip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Encoding"); got != "gzip" {
+			t.Errorf("Content-Encoding = %q, want gzip", got)
+		}
+		reader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Fatalf("gzip.NewReader() error = %v", err)
+		}
+		body, _ := ioutil.ReadAll(reader)
+		if !strings.Contains(string(body), `"method":"eth_blockNumber"`) {
+			t.Errorf("request = %s, want eth_blockNumber call", body)
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		writer.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+		writer.Close()
+	}))
+	defer server.Close()
+
+	got, err := provider.DialHTTP(server.URL, provider.WithGzip()).Call("eth_blockNumber", []interface{}{})
+	if err != nil {
+		t.Fatalf("HTTPProvider.Call() error = %v", err)
+	}
+	if want := `{"jsonrpc":"2.0","id":1,"result":"0x1"}`; string(got) != want {
+		t.Errorf("HTTPProvider.Call() = %s, want %s", got, want)
+	}
+}
+
+func TestDialHTTP_MaxResponseSize(t *testing.T) {
+	response := `{"jsonrpc":"2.0","id":1,"result":"0x1"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		size    int64
+		wantErr error
+	}{
+		{
+			name: "Fits",
+			size: int64(len(response)),
+		},
+		{
+			name:    "Too large",
+			size:    int64(len(response)) - 1,
+			wantErr: provider.ErrResponseTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.DialHTTP(server.URL, provider.WithMaxResponseSize(tt.size)).Call("eth_blockNumber", []interface{}{})
+			if err != tt.wantErr {
+				t.Errorf("HTTPProvider.Call() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDialHTTP_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer server.Close()
+
+	_, err := provider.DialHTTP(server.URL, provider.WithTimeout(20*time.Millisecond)).Call("eth_blockNumber", []interface{}{})
+	if err == nil {
+		t.Errorf("HTTPProvider.Call() error = nil, want timeout")
+	}
+}
+
+func TestDialHTTP_Proxy(t *testing.T) {
+	proxied := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied <- r.URL.String()
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	if _, err := provider.DialHTTP("http://node.invalid:8545", provider.WithProxy(proxyURL)).Call("eth_blockNumber", []interface{}{}); err != nil {
+		t.Fatalf("HTTPProvider.Call() error = %v", err)
+	}
+	if got := <-proxied; got != "http://node.invalid:8545/" {
+		t.Errorf("proxied request URL = %s, want http://node.invalid:8545", got)
+	}
+}
+
+func TestDialHTTP_RootCAs(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	bundle := filepath.Join(dir, "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(bundle, certificate, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.DialHTTP(server.URL).Call("eth_blockNumber", []interface{}{}); err == nil {
+		t.Errorf("HTTPProvider.Call() without the CA error = nil, want unknown authority")
+	}
+
+	pool, err := provider.LoadCABundle(bundle)
+	if err != nil {
+		t.Fatalf("LoadCABundle() error = %v", err)
+	}
+	if _, err := provider.DialHTTP(server.URL, provider.WithRootCAs(pool)).Call("eth_blockNumber", []interface{}{}); err != nil {
+		t.Errorf("HTTPProvider.Call() with the CA error = %v", err)
+	}
+}
+
+func TestDialHTTP_SharedClient(t *testing.T) {
+	shared := &tls.Config{}
+	transport := &http.Transport{TLSClientConfig: &tls.Config{}}
+	client := &http.Client{Transport: transport}
+
+	provider.DialHTTP("https://node.invalid",
+		provider.WithHTTPClient(client),
+		provider.WithTimeout(time.Second),
+		provider.WithRootCAs(x509.NewCertPool()),
+	)
+	provider.DialHTTP("https://node.invalid",
+		provider.WithTLSConfig(shared),
+		provider.WithClientCertificate(tls.Certificate{}),
+	)
+
+	if client.Timeout != 0 {
+		t.Errorf("WithTimeout() changed the timeout of the client to %s", client.Timeout)
+	}
+	if client.Transport != transport || transport.TLSClientConfig.RootCAs != nil {
+		t.Errorf("WithRootCAs() changed the transport of the client")
+	}
+	if len(shared.Certificates) != 0 {
+		t.Errorf("WithClientCertificate() changed the configuration given to WithTLSConfig")
+	}
+}
@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned by a fail-fast RateLimitProvider when no request can be made right now.
+var ErrRateLimited = errors.New("provider: rate limit exceeded")
+
+// Rate is the token bucket of a rate limit: Requests per Period, with bursts up to Burst requests.
+type Rate struct {
+	Requests int
+	Period   time.Duration
+	// Burst is the size of the bucket, Requests by default
+	Burst int
+}
+
+// RateLimitConfig configures a RateLimitProvider.
+type RateLimitConfig struct {
+	// Default limits all the calls, unlimited if Requests is 0
+	Default Rate
+	// Methods limits the calls of each method on top of Default,
+	// for example map[string]Rate{"eth_getLogs": {Requests: 1, Period: time.Second}}
+	Methods map[string]Rate
+	// FailFast returns ErrRateLimited instead of waiting for the limit to allow the call
+	FailFast bool
+}
+
+// RateLimitProvider wraps a provider and limits the rate of the calls, to stay
+// under the quota of a hosted node.
+type RateLimitProvider struct {
+	provider Provider
+	config   RateLimitConfig
+	all      *bucket
+	methods  map[string]*bucket
+}
+
+// NewRateLimitProvider wraps p, limiting its calls as configured.
+func NewRateLimitProvider(p Provider, config RateLimitConfig) *RateLimitProvider {
+	r := &RateLimitProvider{
+		provider: p,
+		config:   config,
+		all:      newBucket(config.Default),
+		methods:  make(map[string]*bucket),
+	}
+	for method, rate := range config.Methods {
+		r.methods[method] = newBucket(rate)
+	}
+
+	return r
+}
+
+// Call makes a request with a specified method and parameters.
+func (r *RateLimitProvider) Call(method string, params interface{}) ([]byte, error) {
+	return r.CallContext(context.Background(), method, params)
+}
+
+// CallContext makes a request once the rate limit allows it.
+func (r *RateLimitProvider) CallContext(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	if err := r.take(ctx, []string{method}); err != nil {
+		return []byte{}, err
+	}
+
+	return r.provider.CallContext(ctx, method, params)
+}
+
+// CallResultContext decodes the result of the call into result once the rate limit allows it.
+func (r *RateLimitProvider) CallResultContext(ctx context.Context, result interface{}, method string, params interface{}) error {
+	if err := r.take(ctx, []string{method}); err != nil {
+		return err
+	}
+
+	return CallResult(ctx, r.provider, result, method, params)
+}
+
+// RawCall calls a method with a JSON encoded list of params.
+func (r *RateLimitProvider) RawCall(method string, args []interface{}) ([]byte, error) {
+	return r.CallContext(context.Background(), method, args)
+}
+
+// BatchCallContext sends the batch once the rate limit allows all of its requests.
+func (r *RateLimitProvider) BatchCallContext(ctx context.Context, batch []BatchElem) error {
+	methods := make([]string, len(batch))
+	for i, elem := range batch {
+		methods[i] = elem.Method
+	}
+	if err := r.take(ctx, methods); err != nil {
+		return err
+	}
+
+	return BatchCallContext(ctx, r.provider, batch)
+}
+
+// SubscribeContext subscribes through the wrapped provider, counting the eth_subscribe call.
+func (r *RateLimitProvider) SubscribeContext(ctx context.Context, args ...interface{}) (*Subscription, error) {
+	subscriber, ok := r.provider.(Subscriber)
+	if !ok {
+		return nil, ErrSubscriptionNotSupported
+	}
+	if err := r.take(ctx, []string{"eth_subscribe"}); err != nil {
+		return nil, err
+	}
+
+	return subscriber.SubscribeContext(ctx, args...)
+}
+
+// Close closes the wrapped provider.
+func (r *RateLimitProvider) Close() error {
+	return r.provider.Close()
+}
+
+// take removes a token for each method from the buckets, waiting for them to
+// refill unless the provider fails fast.
+func (r *RateLimitProvider) take(ctx context.Context, methods []string) error {
+	needed := map[*bucket]float64{}
+	if r.all != nil {
+		needed[r.all] = float64(len(methods))
+	}
+	for _, method := range methods {
+		if b := r.methods[method]; b != nil {
+			needed[b]++
+		}
+	}
+
+	var taken []*bucket
+	for b, n := range needed {
+		wait, ok := b.take(n, !r.config.FailFast)
+		if !ok && r.config.FailFast {
+			for _, t := range taken {
+				t.giveBack(needed[t])
+			}
+			return ErrRateLimited
+		}
+		taken = append(taken, b)
+		if ok {
+			continue
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			for _, t := range taken {
+				t.giveBack(needed[t])
+			}
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// bucket is a token bucket refilled continuously.
+type bucket struct {
+	mu       sync.Mutex
+	rate     float64 // tokens per nanosecond
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newBucket(rate Rate) *bucket {
+	if rate.Requests <= 0 || rate.Period <= 0 {
+		return nil
+	}
+	capacity := rate.Burst
+	if capacity <= 0 {
+		capacity = rate.Requests
+	}
+
+	return &bucket{
+		rate:     float64(rate.Requests) / float64(rate.Period),
+		capacity: float64(capacity),
+		tokens:   float64(capacity),
+		last:     time.Now(),
+	}
+}
+
+// take removes n tokens. If there are not enough it returns how long to wait for them.
+// Reserving takes the tokens anyway, the bucket goes in debt and the caller must
+// wait before using them.
+func (b *bucket) take(n float64, reserve bool) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += float64(now.Sub(b.last)) * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	if b.tokens >= n {
+		b.tokens -= n
+		return 0, true
+	}
+
+	wait := time.Duration((n - b.tokens) / b.rate)
+	if reserve {
+		b.tokens -= n
+	}
+
+	return wait, false
+}
+
+func (b *bucket) giveBack(n float64) {
+	b.mu.Lock()
+	b.tokens += n
+	b.mu.Unlock()
+}
@@ -0,0 +1,71 @@
+package provider_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cleanunicorn/ethereum/provider"
+)
+
+func startEchoNode() *httptest.Server {
+	return httptest.NewServer(echoID(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+}
+
+func TestRateLimitProvider_FailFast(t *testing.T) {
+	server := startEchoNode()
+	defer server.Close()
+
+	p := provider.NewRateLimitProvider(provider.DialHTTP(server.URL), provider.RateLimitConfig{
+		Default: provider.Rate{Requests: 3, Period: time.Hour},
+		Methods: map[string]provider.Rate{
+			"eth_getLogs": {Requests: 1, Period: time.Hour},
+		},
+		FailFast: true,
+	})
+
+	calls := []struct {
+		method string
+		want   error
+	}{
+		{"eth_getLogs", nil},
+		{"eth_getLogs", provider.ErrRateLimited},
+		{"eth_blockNumber", nil},
+		{"eth_blockNumber", nil},
+		{"eth_blockNumber", provider.ErrRateLimited},
+	}
+	for i, call := range calls {
+		if _, err := p.Call(call.method, []interface{}{}); err != call.want {
+			t.Errorf("Call %d %s error = %v, want %v", i, call.method, err, call.want)
+		}
+	}
+}
+
+func TestRateLimitProvider_Blocking(t *testing.T) {
+	server := startEchoNode()
+	defer server.Close()
+
+	p := provider.NewRateLimitProvider(provider.DialHTTP(server.URL), provider.RateLimitConfig{
+		Default: provider.Rate{Requests: 1, Period: 20 * time.Millisecond},
+	})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := p.Call("eth_getBlockByNumber", []interface{}{"0x1", false}); err != nil {
+			t.Fatalf("RateLimitProvider.Call() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("4 calls at 1 per 20ms took %s, want at least 60ms", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := p.CallContext(ctx, "eth_blockNumber", []interface{}{}); err != context.DeadlineExceeded {
+		t.Errorf("RateLimitProvider.CallContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,198 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+)
+
+// Recording is a call saved by a RecordProvider and served by a ReplayProvider.
+type Recording struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	// Response is the raw JSON-RPC response of a successful call
+	Response json.RawMessage `json:"response,omitempty"`
+	// Error is the error returned by the node
+	Error *RPCError `json:"error,omitempty"`
+}
+
+// RecordProvider wraps a provider and records its calls, to be replayed later by a
+// ReplayProvider. The recordings are written to a fixture file when the provider is closed.
+//
+// Only the answers of the node are recorded, transport errors are returned without being saved.
+type RecordProvider struct {
+	provider Provider
+	path     string
+
+	mu         sync.Mutex
+	recordings []Recording
+}
+
+// NewRecordProvider wraps p, recording its calls to the fixture file at path.
+func NewRecordProvider(p Provider, path string) *RecordProvider {
+	return &RecordProvider{
+		provider:   p,
+		path:       path,
+		recordings: []Recording{},
+	}
+}
+
+// Call makes a request with a specified method and parameters.
+func (r *RecordProvider) Call(method string, params interface{}) ([]byte, error) {
+	return r.CallContext(context.Background(), method, params)
+}
+
+// CallContext makes the request with the wrapped provider and records the answer.
+func (r *RecordProvider) CallContext(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	reply, err := r.provider.CallContext(ctx, method, params)
+
+	rpcErr, isRPCError := err.(*RPCError)
+	if err != nil && !isRPCError {
+		return reply, err
+	}
+	paramsJSON, marshalErr := json.Marshal(params)
+	if marshalErr != nil {
+		return reply, err
+	}
+
+	recording := Recording{
+		Method: method,
+		Params: paramsJSON,
+		Error:  rpcErr,
+	}
+	if err == nil {
+		recording.Response = reply
+	}
+	r.mu.Lock()
+	r.recordings = append(r.recordings, recording)
+	r.mu.Unlock()
+
+	return reply, err
+}
+
+// RawCall calls a method with a JSON encoded list of params.
+func (r *RecordProvider) RawCall(method string, args []interface{}) ([]byte, error) {
+	return r.CallContext(context.Background(), method, args)
+}
+
+// Save writes the calls recorded so far to the fixture file.
+func (r *RecordProvider) Save() error {
+	r.mu.Lock()
+	recordingsJSON, err := json.MarshalIndent(r.recordings, "", "    ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.path, recordingsJSON, 0644)
+}
+
+// Close saves the recordings and closes the wrapped provider.
+func (r *RecordProvider) Close() error {
+	if err := r.Save(); err != nil {
+		r.provider.Close()
+		return err
+	}
+
+	return r.provider.Close()
+}
+
+// ReplayProvider answers the calls with the responses saved by a RecordProvider,
+// without a node.
+//
+// A call gets the recording with the same method and params. When the same call was
+// recorded several times, the recordings are served in order and the last one is repeated.
+type ReplayProvider struct {
+	mu         sync.Mutex
+	recordings map[string][]Recording
+}
+
+// NewReplayProvider returns a provider serving the recordings of the fixture file at path.
+func NewReplayProvider(path string) (*ReplayProvider, error) {
+	fixture, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var recordings []Recording
+	if err := json.Unmarshal(fixture, &recordings); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %s", path, err)
+	}
+
+	r := &ReplayProvider{
+		recordings: make(map[string][]Recording),
+	}
+	for _, recording := range recordings {
+		key, err := replayKey(recording.Method, recording.Params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fixture %s: %s", path, err)
+		}
+		r.recordings[key] = append(r.recordings[key], recording)
+	}
+
+	return r, nil
+}
+
+// Call makes a request with a specified method and parameters.
+func (r *ReplayProvider) Call(method string, params interface{}) ([]byte, error) {
+	return r.CallContext(context.Background(), method, params)
+}
+
+// CallContext returns the recorded answer of the call.
+func (r *ReplayProvider) CallContext(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return []byte{}, err
+	}
+
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return []byte{}, err
+	}
+	key, err := replayKey(method, paramsJSON)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	r.mu.Lock()
+	recordings := r.recordings[key]
+	if len(recordings) == 0 {
+		r.mu.Unlock()
+		return []byte{}, fmt.Errorf("no recording for %s with params %s", method, paramsJSON)
+	}
+	recording := recordings[0]
+	if len(recordings) > 1 {
+		r.recordings[key] = recordings[1:]
+	}
+	r.mu.Unlock()
+
+	if recording.Error != nil {
+		return []byte{}, recording.Error
+	}
+
+	return recording.Response, nil
+}
+
+// RawCall calls a method with a JSON encoded list of params.
+func (r *ReplayProvider) RawCall(method string, args []interface{}) ([]byte, error) {
+	return r.CallContext(context.Background(), method, args)
+}
+
+// Close does nothing, there is no connection to release.
+func (r *ReplayProvider) Close() error {
+	return nil
+}
+
+// replayKey identifies a call, ignoring the formatting of its params.
+func replayKey(method string, params json.RawMessage) (string, error) {
+	var compact bytes.Buffer
+	if len(params) > 0 {
+		if err := json.Compact(&compact, params); err != nil {
+			return "", err
+		}
+	}
+
+	return method + compact.String(), nil
+}
@@ -0,0 +1,86 @@
+package provider_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cleanunicorn/ethereum/provider"
+)
+
+func TestRecordProvider_Replay(t *testing.T) {
+	block := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64 `json:"id"`
+			Method string `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch req.Method {
+		case "eth_blockNumber":
+			block++
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":"0x%x"}`, req.ID, block)
+		default:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"error":{"code":-32601,"message":"method not found"}}`, req.ID)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fixture := filepath.Join(dir, "calls.golden")
+
+	calls := []struct {
+		method  string
+		params  interface{}
+		want    string
+		wantErr string
+	}{
+		{method: "eth_blockNumber", params: []interface{}{}, want: "0x1"},
+		{method: "eth_blockNumber", params: []interface{}{}, want: "0x2"},
+		{method: "eth_unknown", params: []interface{}{"0x1", true}, wantErr: "code: -32601, error: method not found"},
+	}
+
+	recorder := provider.NewRecordProvider(provider.DialHTTP(server.URL), fixture)
+	for _, call := range calls {
+		recorder.Call(call.method, call.params)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("RecordProvider.Close() error = %v", err)
+	}
+	server.Close()
+
+	replay, err := provider.NewReplayProvider(fixture)
+	if err != nil {
+		t.Fatalf("NewReplayProvider() error = %v", err)
+	}
+	// The last recording of a call is repeated
+	calls = append(calls, calls[1])
+	for i, call := range calls {
+		reply, err := replay.Call(call.method, call.params)
+		if call.wantErr != "" {
+			if err == nil || err.Error() != call.wantErr {
+				t.Errorf("Call %d ReplayProvider.Call() error = %v, want %s", i, err, call.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Call %d ReplayProvider.Call() error = %v", i, err)
+		}
+		if got := result(t, reply); got != call.want {
+			t.Errorf("Call %d ReplayProvider.Call() = %s, want %s", i, got, call.want)
+		}
+	}
+
+	if _, err := replay.Call("eth_blockNumber", []interface{}{"latest"}); err == nil {
+		t.Errorf("ReplayProvider.Call() error = nil for a call not recorded")
+	}
+}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+)
+
+// ResultCaller is implemented by the providers able to decode the result of a call
+// as it is received, in a single pass instead of returning the raw response to be
+// decoded again. The JSON decoder still buffers the response while decoding it.
+//
+// The wrappers working on raw responses, CacheProvider, ChainProvider and RecordProvider,
+// do not implement it: CallResult decodes the raw response they return.
+type ResultCaller interface {
+	CallResultContext(ctx context.Context, result interface{}, method string, params interface{}) error
+}
+
+// CallResult calls method through p and decodes the result of the response into result,
+// which should be a pointer. A null result leaves it untouched.
+// Providers not implementing ResultCaller have their raw response decoded.
+func CallResult(ctx context.Context, p Provider, result interface{}, method string, params interface{}) error {
+	if r, ok := p.(ResultCaller); ok {
+		return r.CallResultContext(ctx, result, method, params)
+	}
+
+	reply, err := p.CallContext(ctx, method, params)
+	if err != nil {
+		return err
+	}
+
+	return unmarshalResult(reply, result)
+}
+
+// unmarshalResult decodes the result of the raw response reply into result.
+func unmarshalResult(reply []byte, result interface{}) error {
+	response := struct {
+		Result interface{} `json:"result"`
+	}{
+		Result: result,
+	}
+
+	return json.Unmarshal(reply, &response)
+}
+
+// CallResultContext makes a request with a specified method and parameters and decodes
+// the result of the response into result while it is received.
+func (c *HTTPProvider) CallResultContext(ctx context.Context, result interface{}, method string, params interface{}) error {
+	id, dataJSON, err := c.encodeRequest(method, params)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := c.startSpan(ctx, method, id)
+	err = c.receiveResult(ctx, span, dataJSON, result, id)
+	span.end(err)
+
+	return err
+}
+
+// receiveResult sends the request and decodes the result of the response while it is received.
+func (c *HTTPProvider) receiveResult(ctx context.Context, span *callSpan, dataJSON []byte, result interface{}, id uint64) error {
+	response, err := c.send(ctx, span, dataJSON)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	// The body of an HTTP error is kept for the *HTTPError, it is read whole
+	if response.StatusCode/100 != 2 {
+		body, err := ioutil.ReadAll(c.limit(response.Body))
+		if err != nil {
+			return err
+		}
+		span.set(AttributeResponseSize, len(body))
+		if err, ok := checkResponseError(body).(*RPCError); ok {
+			return err
+		}
+		return &HTTPError{
+			StatusCode: response.StatusCode,
+			Status:     response.Status,
+			Body:       body,
+		}
+	}
+
+	counter := &countingReader{r: response.Body}
+	err = decodeResponse(c.limit(counter), result, id)
+	span.set(AttributeResponseSize, counter.n)
+
+	return err
+}
+
+// limitedReader reads from r until n bytes were read, then fails with ErrResponseTooLarge.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// Only fail when there is something left to read
+		var b [1]byte
+		if n, _ := l.r.Read(b[:]); n > 0 {
+			return 0, ErrResponseTooLarge
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	return n, err
+}
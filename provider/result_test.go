@@ -0,0 +1,199 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cleanunicorn/ethereum/provider"
+)
+
+func TestHTTPProvider_CallResultContext(t *testing.T) {
+	// A block with many transactions, larger than the buffers of the decoder
+	block := `{"number":"0x1","transactions":["` + strings.Repeat("0xab", 100000) + `"]}`
+
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		size     int64
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "Result",
+			response: `{"jsonrpc":"2.0","id":1,"result":` + block + `}`,
+			want:     "0x1",
+		},
+		{
+			name:     "Result before the id",
+			response: `{"jsonrpc":"2.0","result":` + block + `,"id":1}`,
+			want:     "0x1",
+		},
+		{
+			name:     "Null result",
+			response: `{"jsonrpc":"2.0","id":1,"result":null}`,
+		},
+		{
+			name:     "Fits",
+			response: `{"jsonrpc":"2.0","id":1,"result":` + block + `}`,
+			size:     int64(len(block) + 34),
+			want:     "0x1",
+		},
+		{
+			name:     "Too large",
+			response: `{"jsonrpc":"2.0","id":1,"result":` + block + `}`,
+			size:     int64(len(block)),
+			wantErr:  provider.ErrResponseTooLarge,
+		},
+		{
+			name:     "RPC error",
+			response: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"nonce too low"}}`,
+			wantErr:  provider.ErrNonceTooLow,
+		},
+		{
+			name:     "RPC error with HTTP error status",
+			status:   http.StatusBadRequest,
+			response: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"nonce too low"}}`,
+			wantErr:  provider.ErrNonceTooLow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(echoID(func(w http.ResponseWriter, r *http.Request) {
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			var got struct {
+				Number       string   `json:"number"`
+				Transactions []string `json:"transactions"`
+			}
+			p := provider.DialHTTP(server.URL, provider.WithMaxResponseSize(tt.size))
+			err := p.CallResultContext(context.Background(), &got, "eth_getBlockByNumber", []interface{}{"0x1", false})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("HTTPProvider.CallResultContext() error = %v, want %v", err, tt.wantErr)
+			}
+			if got.Number != tt.want {
+				t.Errorf("HTTPProvider.CallResultContext() number = %q, want %q", got.Number, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPProvider_CallResultContextErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		wantErr  interface{}
+	}{
+		{
+			name:     "HTTP error",
+			status:   http.StatusServiceUnavailable,
+			response: `busy`,
+			wantErr:  new(*provider.HTTPError),
+		},
+		{
+			name:     "ID mismatch",
+			response: `{"jsonrpc":"2.0","id":1000,"result":"0x1"}`,
+			wantErr:  new(*provider.IDMismatchError),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			var got string
+			err := provider.DialHTTP(server.URL).CallResultContext(context.Background(), &got, "eth_blockNumber", []interface{}{})
+			if !errors.As(err, tt.wantErr) {
+				t.Errorf("HTTPProvider.CallResultContext() error = %v, want %T", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCallResult(t *testing.T) {
+	m := provider.NewMockProvider()
+	m.Expect("eth_blockNumber", []interface{}{}).Return("0x10")
+	m.Expect("eth_getBlockByNumber", provider.AnyParams).ReturnError(-32000, "header not found")
+
+	var number string
+	if err := provider.CallResult(context.Background(), m, &number, "eth_blockNumber", []interface{}{}); err != nil || number != "0x10" {
+		t.Errorf("CallResult() = %q, %v, want 0x10", number, err)
+	}
+	var block map[string]interface{}
+	if err := provider.CallResult(context.Background(), m, &block, "eth_getBlockByNumber", []interface{}{"0x1", false}); err == nil {
+		t.Errorf("CallResult() error = nil, want the RPC error")
+	}
+}
+
+// resultCaller counts the calls decoded by CallResultContext, the raw calls fail.
+type resultCaller struct {
+	*provider.MockProvider
+	results int
+}
+
+func (r *resultCaller) CallResultContext(ctx context.Context, result interface{}, method string, params interface{}) error {
+	r.results++
+	*result.(*string) = "0x10"
+	return nil
+}
+
+func TestCallResult_Wrappers(t *testing.T) {
+	tests := []struct {
+		name string
+		wrap func(p provider.Provider) provider.Provider
+	}{
+		{
+			name: "Retry",
+			wrap: func(p provider.Provider) provider.Provider {
+				return provider.NewRetryProvider(p, provider.RetryConfig{})
+			},
+		},
+		{
+			name: "Rate limit",
+			wrap: func(p provider.Provider) provider.Provider {
+				return provider.NewRateLimitProvider(p, provider.RateLimitConfig{})
+			},
+		},
+		{
+			name: "Failover",
+			wrap: func(p provider.Provider) provider.Provider {
+				return provider.NewMultiProvider(provider.MultiConfig{Mode: provider.Failover}, p)
+			},
+		},
+		{
+			name: "Metrics",
+			wrap: func(p provider.Provider) provider.Provider {
+				return provider.NewMetricsProvider(p, provider.MetricsConfig{})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &resultCaller{MockProvider: provider.NewMockProvider()}
+
+			var number string
+			err := provider.CallResult(context.Background(), tt.wrap(inner), &number, "eth_blockNumber", []interface{}{})
+			if err != nil || number != "0x10" {
+				t.Fatalf("CallResult() = %q, %v, want 0x10", number, err)
+			}
+			if inner.results != 1 {
+				t.Errorf("CallResultContext of the wrapped provider called %d times, want 1", inner.results)
+			}
+		})
+	}
+}
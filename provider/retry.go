@@ -0,0 +1,281 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Default retry parameters
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = 5 * time.Second
+	defaultRetryMultiplier     = 2
+	defaultRetryJitter         = 0.2
+)
+
+// nonIdempotentMethods change the state of the node, sending them twice may do the work twice
+var nonIdempotentMethods = map[string]bool{
+	"eth_sendRawTransaction":   true,
+	"eth_sendTransaction":      true,
+	"personal_sendTransaction": true,
+	"personal_newAccount":      true,
+	"eth_submitWork":           true,
+	"eth_submitHashrate":       true,
+	"shh_post":                 true,
+}
+
+// RetryConfig configures a RetryProvider.
+// The zero value of each field selects its default.
+type RetryConfig struct {
+	// MaxAttempts is the number of times a call is made before giving up, 3 by default
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, 100ms by default
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts, 5s by default
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after each attempt, 2 by default
+	Multiplier float64
+	// Jitter randomizes the delay by up to this fraction, 0.2 by default
+	Jitter float64
+	// ShouldRetry decides if a failed call is retried, DefaultShouldRetry by default
+	ShouldRetry func(method string, err error) bool
+}
+
+// RetryProvider wraps a provider and retries the calls failing with a transient error,
+// waiting an exponentially growing delay between the attempts.
+type RetryProvider struct {
+	provider Provider
+	config   RetryConfig
+}
+
+// NewRetryProvider wraps p, retrying its calls as configured.
+func NewRetryProvider(p Provider, config RetryConfig) *RetryProvider {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaultRetryInitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if config.Multiplier < 1 {
+		config.Multiplier = defaultRetryMultiplier
+	}
+	if config.Jitter <= 0 {
+		config.Jitter = defaultRetryJitter
+	}
+	if config.ShouldRetry == nil {
+		config.ShouldRetry = DefaultShouldRetry
+	}
+
+	return &RetryProvider{
+		provider: p,
+		config:   config,
+	}
+}
+
+// DefaultShouldRetry retries the rate limited calls, the gateway errors and the network failures.
+//
+// Calls changing the state, such as eth_sendRawTransaction, are only retried when the
+// request surely did not reach the node: rate limited or connection refused.
+//
+// A range too large, see ErrRangeTooLarge, fails again with the same query and is not retried.
+func DefaultShouldRetry(method string, err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, ErrRangeTooLarge) {
+		return false
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		switch httpErr.StatusCode {
+		case 429:
+			return true
+		case 502, 503, 504:
+			return !nonIdempotentMethods[method]
+		}
+		return false
+	}
+
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		// -32005 is the "limit exceeded" code used by the hosted nodes
+		return rpcErr.Code == -32005
+	}
+
+	if isDialError(err) {
+		return true
+	}
+	if nonIdempotentMethods[method] {
+		return false
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && (netErr.Timeout() || netErr.Temporary()) {
+		return true
+	}
+	message := err.Error()
+
+	return strings.Contains(message, "connection reset") ||
+		strings.Contains(message, "broken pipe") ||
+		strings.HasSuffix(message, "EOF")
+}
+
+// isDialError reports whether the connection to the endpoint could not be made at all.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	if strings.Contains(err.Error(), syscall.ECONNREFUSED.Error()) {
+		return true
+	}
+
+	return false
+}
+
+// Call makes a request with a specified method and parameters.
+func (r *RetryProvider) Call(method string, params interface{}) ([]byte, error) {
+	return r.CallContext(context.Background(), method, params)
+}
+
+// CallContext makes a request, retrying it until it succeeds, the error is not
+// retriable, the attempts are exhausted or the context is done.
+func (r *RetryProvider) CallContext(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		reply, err := r.provider.CallContext(ctx, method, params)
+		if err == nil || attempt >= r.config.MaxAttempts || !r.config.ShouldRetry(method, unwrapURLError(err)) {
+			return reply, err
+		}
+
+		log.Debugf("Retrying %s after attempt %d failed, err: %s", method, attempt, err)
+		if err := r.wait(ctx, attempt); err != nil {
+			return []byte{}, err
+		}
+	}
+}
+
+// CallResultContext decodes the result of the call into result, retrying the call like CallContext.
+func (r *RetryProvider) CallResultContext(ctx context.Context, result interface{}, method string, params interface{}) error {
+	for attempt := 1; ; attempt++ {
+		err := CallResult(ctx, r.provider, result, method, params)
+		if err == nil || attempt >= r.config.MaxAttempts || !r.config.ShouldRetry(method, unwrapURLError(err)) {
+			return err
+		}
+
+		log.Debugf("Retrying %s after attempt %d failed, err: %s", method, attempt, err)
+		if err := r.wait(ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+// RawCall calls a method with a JSON encoded list of params.
+func (r *RetryProvider) RawCall(method string, args []interface{}) ([]byte, error) {
+	return r.CallContext(context.Background(), method, args)
+}
+
+// BatchCallContext sends the batch, then sends again the requests which failed
+// with a retriable error.
+func (r *RetryProvider) BatchCallContext(ctx context.Context, batch []BatchElem) error {
+	pending := make([]int, len(batch))
+	for i := range batch {
+		pending[i] = i
+	}
+
+	for attempt := 1; ; attempt++ {
+		sub := make([]BatchElem, len(pending))
+		for j, i := range pending {
+			sub[j] = BatchElem{
+				Method: batch[i].Method,
+				Params: batch[i].Params,
+			}
+		}
+
+		err := BatchCallContext(ctx, r.provider, sub)
+		if err != nil {
+			retry := attempt < r.config.MaxAttempts
+			for _, elem := range sub {
+				retry = retry && r.config.ShouldRetry(elem.Method, unwrapURLError(err))
+			}
+			if !retry {
+				return err
+			}
+		} else {
+			var failed []int
+			for j, i := range pending {
+				batch[i].Result, batch[i].Error = sub[j].Result, sub[j].Error
+				if sub[j].Error != nil && r.config.ShouldRetry(sub[j].Method, unwrapURLError(sub[j].Error)) {
+					failed = append(failed, i)
+				}
+			}
+			if len(failed) == 0 || attempt >= r.config.MaxAttempts {
+				return nil
+			}
+			pending = failed
+		}
+
+		log.Debugf("Retrying %d batch requests after attempt %d", len(pending), attempt)
+		if err := r.wait(ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+// SubscribeContext subscribes through the wrapped provider, without retrying.
+func (r *RetryProvider) SubscribeContext(ctx context.Context, args ...interface{}) (*Subscription, error) {
+	subscriber, ok := r.provider.(Subscriber)
+	if !ok {
+		return nil, ErrSubscriptionNotSupported
+	}
+
+	return subscriber.SubscribeContext(ctx, args...)
+}
+
+// Close closes the wrapped provider.
+func (r *RetryProvider) Close() error {
+	return r.provider.Close()
+}
+
+// wait sleeps before the next attempt, returning early if the context is done.
+func (r *RetryProvider) wait(ctx context.Context, attempt int) error {
+	backoff := float64(r.config.InitialBackoff) * math.Pow(r.config.Multiplier, float64(attempt-1))
+	if backoff > float64(r.config.MaxBackoff) {
+		backoff = float64(r.config.MaxBackoff)
+	}
+	backoff += backoff * r.config.Jitter * (2*rand.Float64() - 1)
+
+	timer := time.NewTimer(time.Duration(backoff))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// unwrapURLError returns the error behind the *url.Error returned by the HTTP client.
+func unwrapURLError(err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		return urlErr.Err
+	}
+
+	return err
+}
@@ -0,0 +1,153 @@
+package provider_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cleanunicorn/ethereum/provider"
+)
+
+func TestRetryProvider(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		failures     int
+		status       int
+		wantErr      bool
+		wantAttempts int
+	}{
+		{
+			name:         "Rate limited call succeeds on the third attempt",
+			method:       "eth_blockNumber",
+			failures:     2,
+			status:       http.StatusTooManyRequests,
+			wantAttempts: 3,
+		},
+		{
+			name:         "Attempts are exhausted",
+			method:       "eth_blockNumber",
+			failures:     5,
+			status:       http.StatusBadGateway,
+			wantErr:      true,
+			wantAttempts: 3,
+		},
+		{
+			name:         "Bad gateway is not retried for eth_sendRawTransaction",
+			method:       "eth_sendRawTransaction",
+			failures:     1,
+			status:       http.StatusBadGateway,
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+		{
+			name:         "Rate limited eth_sendRawTransaction is retried",
+			method:       "eth_sendRawTransaction",
+			failures:     1,
+			status:       http.StatusTooManyRequests,
+			wantAttempts: 2,
+		},
+		{
+			name:         "Client errors are not retried",
+			method:       "eth_blockNumber",
+			failures:     1,
+			status:       http.StatusUnauthorized,
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(echoID(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if attempts <= tt.failures {
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+			}))
+			defer server.Close()
+
+			p := provider.NewRetryProvider(provider.DialHTTP(server.URL), provider.RetryConfig{
+				InitialBackoff: time.Millisecond,
+			})
+			_, err := p.Call(tt.method, []interface{}{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RetryProvider.Call() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("RetryProvider.Call() made %d attempts, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryProvider_ConnectionRefused(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	endpoint := server.URL
+	server.Close()
+
+	shouldRetry := 0
+	p := provider.NewRetryProvider(provider.DialHTTP(endpoint), provider.RetryConfig{
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+		ShouldRetry: func(method string, err error) bool {
+			shouldRetry++
+			return provider.DefaultShouldRetry(method, err)
+		},
+	})
+	if _, err := p.Call("eth_sendRawTransaction", []interface{}{"0x"}); err == nil {
+		t.Fatalf("RetryProvider.Call() expected error")
+	}
+	if shouldRetry != 1 {
+		t.Errorf("ShouldRetry called %d times, want 1", shouldRetry)
+	}
+	if !provider.DefaultShouldRetry("eth_sendRawTransaction", &provider.HTTPError{StatusCode: 429}) {
+		t.Errorf("DefaultShouldRetry() = false for a rate limited call")
+	}
+}
+
+func TestDefaultShouldRetry_Wrapped(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "Rate limited",
+			err:  fmt.Errorf("middleware: %w", &provider.HTTPError{StatusCode: 429}),
+			want: true,
+		},
+		{
+			name: "Limit exceeded",
+			err:  fmt.Errorf("receipt of 0x1: %w", &provider.RPCError{Code: -32005, Message: "limit exceeded"}),
+			want: true,
+		},
+		{
+			name: "Range too large",
+			err:  fmt.Errorf("logs: %w", &provider.RPCError{Code: -32005, Message: "query returned more than 10000 results"}),
+			want: false,
+		},
+		{
+			name: "Reverted",
+			err:  fmt.Errorf("receipt of 0x1: %w", &provider.RPCError{Code: 3, Message: "execution reverted"}),
+			want: false,
+		},
+		{
+			name: "Canceled",
+			err:  fmt.Errorf("middleware: %w", context.Canceled),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := provider.DefaultShouldRetry("eth_blockNumber", tt.err); got != tt.want {
+				t.Errorf("DefaultShouldRetry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,402 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrClosed is returned when a call is made on, or interrupted by, a closed connection.
+var ErrClosed = errors.New("provider: connection closed")
+
+// ErrSubscriptionNotSupported is returned when subscribing through a provider without push notifications.
+var ErrSubscriptionNotSupported = errors.New("provider: subscriptions not supported")
+
+// Subscriber is implemented by the providers able to deliver eth_subscribe notifications.
+type Subscriber interface {
+	SubscribeContext(ctx context.Context, args ...interface{}) (*Subscription, error)
+}
+
+// messageConn is a connection exchanging whole JSON-RPC messages.
+type messageConn interface {
+	readMessage() ([]byte, error)
+	writeMessage(message []byte) error
+	close() error
+}
+
+// stream sends JSON-RPC requests over a persistent connection.
+//
+// Concurrent calls are multiplexed on the same connection and matched to their
+// responses by request ID.
+type stream struct {
+	conn    messageConn
+	writeMu sync.Mutex
+	nextID  uint64
+
+	mu      sync.Mutex
+	pending map[uint64]*pendingCall
+	subs    map[string]*Subscription
+	err     error
+	closed  chan struct{}
+}
+
+type pendingCall struct {
+	response chan []byte
+	sub      *Subscription
+	// canceled is set when the caller of eth_subscribe gave up, the subscription the
+	// node creates anyway is cancelled as soon as its id is known
+	canceled bool
+}
+
+func newStream(conn messageConn) *stream {
+	c := &stream{
+		conn:    conn,
+		pending: make(map[uint64]*pendingCall),
+		subs:    make(map[string]*Subscription),
+		closed:  make(chan struct{}),
+	}
+	go c.read()
+
+	return c
+}
+
+func (c *stream) subscribe(ctx context.Context, args []interface{}) (*Subscription, error) {
+	sub := newSubscription(c)
+	if _, err := c.call(ctx, "eth_subscribe", args, sub); err != nil {
+		// The response may have been received, and the subscription registered, after all
+		c.mu.Lock()
+		registered := sub.ID != "" && c.subs[sub.ID] == sub
+		if registered {
+			delete(c.subs, sub.ID)
+		}
+		c.mu.Unlock()
+		if registered {
+			c.cancelSubscription(sub.ID)
+		}
+		sub.close(nil)
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// cancelSubscription sends eth_unsubscribe for a subscription nobody is listening to,
+// without waiting for the answer.
+func (c *stream) cancelSubscription(id string) {
+	go c.call(context.Background(), "eth_unsubscribe", []interface{}{id}, nil)
+}
+
+func (c *stream) close() error {
+	select {
+	case <-c.closed:
+		return nil
+	default:
+	}
+
+	c.shutdown(ErrClosed)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	return c.conn.close()
+}
+
+func (c *stream) call(ctx context.Context, method string, params interface{}, sub *Subscription) ([]byte, error) {
+	id := atomic.AddUint64(&c.nextID, 1)
+	data := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+		"id":      id,
+	}
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	call := &pendingCall{
+		response: make(chan []byte, 1),
+		sub:      sub,
+	}
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return []byte{}, c.err
+	}
+	c.pending[id] = call
+	c.mu.Unlock()
+
+	c.writeMu.Lock()
+	err = c.conn.writeMessage(dataJSON)
+	c.writeMu.Unlock()
+	if err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return []byte{}, err
+	}
+
+	select {
+	case body := <-call.response:
+		if err := checkResponseError(body); err != nil {
+			return []byte{}, err
+		}
+		return body, nil
+	case <-c.closed:
+		return []byte{}, c.closeErr()
+	case <-ctx.Done():
+		c.mu.Lock()
+		if sub != nil {
+			// Keep waiting for the id of the subscription to cancel it
+			call.canceled = true
+		} else {
+			delete(c.pending, id)
+		}
+		c.mu.Unlock()
+		return []byte{}, ctx.Err()
+	}
+}
+
+// read dispatches the incoming messages to the pending calls and subscriptions
+// until the connection fails.
+func (c *stream) read() {
+	for {
+		message, err := c.conn.readMessage()
+		if err != nil {
+			c.shutdown(err)
+			return
+		}
+
+		var msg struct {
+			ID     *uint64         `json:"id"`
+			Method string          `json:"method"`
+			Result json.RawMessage `json:"result"`
+			Params struct {
+				Subscription string          `json:"subscription"`
+				Result       json.RawMessage `json:"result"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(message, &msg); err != nil {
+			continue
+		}
+
+		if msg.ID == nil {
+			if msg.Method == "eth_subscription" {
+				c.mu.Lock()
+				sub := c.subs[msg.Params.Subscription]
+				c.mu.Unlock()
+				if sub != nil {
+					sub.deliver(msg.Params.Result)
+				}
+			}
+			continue
+		}
+
+		c.mu.Lock()
+		call := c.pending[*msg.ID]
+		delete(c.pending, *msg.ID)
+		// Register the subscription before reading further so no notification is lost
+		var canceled string
+		if call != nil && call.sub != nil {
+			if err := json.Unmarshal(msg.Result, &call.sub.ID); err == nil {
+				if call.canceled {
+					canceled = call.sub.ID
+				} else {
+					c.subs[call.sub.ID] = call.sub
+				}
+			}
+		}
+		c.mu.Unlock()
+
+		if canceled != "" {
+			c.cancelSubscription(canceled)
+		}
+		if call != nil {
+			call.response <- message
+		}
+	}
+}
+
+func (c *stream) shutdown(err error) {
+	c.mu.Lock()
+	select {
+	case <-c.closed:
+		c.mu.Unlock()
+		return
+	default:
+	}
+	c.err = err
+	close(c.closed)
+	subs := c.subs
+	c.subs = make(map[string]*Subscription)
+	c.pending = make(map[uint64]*pendingCall)
+	c.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.close(err)
+	}
+}
+
+func (c *stream) closeErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+func (c *stream) unsubscribe(sub *Subscription) error {
+	c.mu.Lock()
+	_, ok := c.subs[sub.ID]
+	delete(c.subs, sub.ID)
+	c.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	_, err := c.call(context.Background(), "eth_unsubscribe", []interface{}{sub.ID}, nil)
+	return err
+}
+
+// Subscription is an active eth_subscribe subscription.
+//
+// Notifications are queued so a slow reader never blocks the connection.
+type Subscription struct {
+	// ID is the subscription id assigned by the node
+	ID string
+
+	stream        *stream
+	notifications chan json.RawMessage
+	errors        chan error
+
+	mu     sync.Mutex
+	queue  []json.RawMessage
+	wake   chan struct{}
+	done   chan struct{}
+	err    error
+	once   sync.Once
+	closed bool
+	// ended is set once the errors channel is closed
+	ended bool
+}
+
+func newSubscription(c *stream) *Subscription {
+	sub := &Subscription{
+		stream:        c,
+		notifications: make(chan json.RawMessage),
+		errors:        make(chan error, 1),
+		wake:          make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+	go sub.forward()
+
+	return sub
+}
+
+// Notifications returns the channel delivering the result of each notification.
+// The channel is closed when the subscription ends.
+func (s *Subscription) Notifications() <-chan json.RawMessage {
+	return s.notifications
+}
+
+// Err returns a channel receiving the errors reported with ReportError and the error
+// that ended the subscription, if any. The channel is closed when the subscription ends.
+func (s *Subscription) Err() <-chan error {
+	return s.errors
+}
+
+// Done returns a channel closed when the subscription ends.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// ReportError sends err on the Err channel without ending the subscription, for the
+// errors found processing the notifications. It is dropped if the previous error was
+// not received, the error ending the subscription is always delivered.
+func (s *Subscription) ReportError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	select {
+	case s.errors <- err:
+	default:
+	}
+}
+
+// Unsubscribe calls eth_unsubscribe and ends the subscription.
+func (s *Subscription) Unsubscribe() error {
+	err := s.stream.unsubscribe(s)
+	s.close(nil)
+
+	return err
+}
+
+func (s *Subscription) deliver(result json.RawMessage) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.queue = append(s.queue, result)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Subscription) close(err error) {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		s.err = err
+		s.mu.Unlock()
+		close(s.done)
+	})
+}
+
+// forward moves the queued notifications to the notification channel.
+func (s *Subscription) forward() {
+	defer func() {
+		s.mu.Lock()
+		if s.err != nil {
+			// Make room for the error ending the subscription
+			select {
+			case <-s.errors:
+			default:
+			}
+			s.errors <- s.err
+		}
+		s.ended = true
+		close(s.errors)
+		s.mu.Unlock()
+		close(s.notifications)
+	}()
+
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			select {
+			case <-s.wake:
+				continue
+			case <-s.done:
+				return
+			}
+		}
+		next := s.queue[0]
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		select {
+		case s.notifications <- next:
+		case <-s.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// Tracer starts the spans of the JSON-RPC calls, it adapts a tracing library such as
+// OpenTelemetry to the providers.
+//
+// Start is given the context of the call, which carries the parent span, and returns
+// the context of the new span. The request is sent with the returned context, so a
+// tracer can propagate the span to the node with ContextWithHeader.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span traces a single call, it is ended once the response is received.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Attributes set on the spans, named after the OpenTelemetry semantic conventions.
+const (
+	AttributeRPCSystem    = "rpc.system"
+	AttributeRPCMethod    = "rpc.method"
+	AttributeRequestID    = "rpc.jsonrpc.request_id"
+	AttributeErrorCode    = "rpc.jsonrpc.error_code"
+	AttributeBatchSize    = "rpc.jsonrpc.batch_size"
+	AttributeURL          = "url.full"
+	AttributeHTTPStatus   = "http.response.status_code"
+	AttributeRequestSize  = "http.request.body.size"
+	AttributeResponseSize = "http.response.body.size"
+)
+
+// WithTracer traces the calls of the provider with tracer, a span for each call and
+// for each batch, named after the method. The other providers, such as the WebSocket
+// and IPC ones, are traced by NewTracingProvider.
+func WithTracer(tracer Tracer) HTTPOption {
+	return func(p *HTTPProvider) {
+		p.tracer = tracer
+	}
+}
+
+// callSpan is the span of a call, doing nothing when the provider has no tracer.
+type callSpan struct {
+	span Span
+}
+
+// startSpan starts the span of a request sent to the endpoint, the id of the request is
+// set unless it is 0.
+func (c *HTTPProvider) startSpan(ctx context.Context, name string, id uint64) (context.Context, *callSpan) {
+	if c.tracer == nil {
+		return ctx, &callSpan{}
+	}
+
+	ctx, span := c.tracer.Start(ctx, name)
+	s := &callSpan{span: span}
+	s.set(AttributeRPCSystem, "jsonrpc")
+	s.set(AttributeURL, c.HTTPEndpoint)
+	if id != 0 {
+		s.set(AttributeRPCMethod, name)
+		s.set(AttributeRequestID, id)
+	}
+
+	return ctx, s
+}
+
+func (s *callSpan) set(key string, value interface{}) {
+	if s.span != nil {
+		s.span.SetAttribute(key, value)
+	}
+}
+
+// end records the error of the call, if any, and ends the span.
+func (s *callSpan) end(err error) {
+	if s.span == nil {
+		return
+	}
+
+	if err != nil {
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) {
+			s.span.SetAttribute(AttributeErrorCode, rpcErr.Code)
+		}
+		s.span.RecordError(err)
+	}
+	s.span.End()
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
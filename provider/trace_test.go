@@ -0,0 +1,200 @@
+package provider_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/cleanunicorn/ethereum/provider"
+)
+
+type spanKey struct{}
+
+// testTracer records the spans, it sends the name of the current span in the Traceparent header
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+type testSpan struct {
+	name       string
+	parent     string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, provider.Span) {
+	span := &testSpan{
+		name:       name,
+		attributes: make(map[string]interface{}),
+	}
+	if parent, ok := ctx.Value(spanKey{}).(*testSpan); ok {
+		span.parent = parent.name
+	}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+
+	ctx = context.WithValue(ctx, spanKey{}, span)
+	return provider.ContextWithHeader(ctx, http.Header{"Traceparent": {name}}), span
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) { s.attributes[key] = value }
+func (s *testSpan) RecordError(err error)                      { s.err = err }
+func (s *testSpan) End()                                       { s.ended = true }
+
+func TestHTTPProvider_Tracer(t *testing.T) {
+	traceparent := make(chan string, 1)
+	server := httptest.NewServer(echoID(func(w http.ResponseWriter, r *http.Request) {
+		traceparent <- r.Header.Get("Traceparent")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"header not found"}}`))
+	}))
+	defer server.Close()
+
+	tracer := &testTracer{}
+	ctx, parent := tracer.Start(context.Background(), "handler")
+	p := provider.DialHTTP(server.URL, provider.WithTracer(tracer))
+	if _, err := p.CallContext(ctx, "eth_getBlockByNumber", []interface{}{"0x1", false}); err == nil {
+		t.Fatalf("HTTPProvider.CallContext() error = nil, want the RPC error")
+	}
+	parent.End()
+
+	if got := <-traceparent; got != "eth_getBlockByNumber" {
+		t.Errorf("Traceparent header = %q, want the span of the call", got)
+	}
+	if len(tracer.spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(tracer.spans))
+	}
+	span := tracer.spans[1]
+	if span.name != "eth_getBlockByNumber" || span.parent != "handler" || !span.ended || span.err == nil {
+		t.Errorf("span = %+v, want an ended eth_getBlockByNumber span with an error, child of handler", span)
+	}
+	want := map[string]interface{}{
+		provider.AttributeRPCSystem:   "jsonrpc",
+		provider.AttributeRPCMethod:   "eth_getBlockByNumber",
+		provider.AttributeRequestID:   uint64(1),
+		provider.AttributeURL:         server.URL,
+		provider.AttributeHTTPStatus:  http.StatusOK,
+		provider.AttributeErrorCode:   -32000,
+		provider.AttributeRequestSize: len(`{"id":1,"jsonrpc":"2.0","method":"eth_getBlockByNumber","params":["0x1",false]}`),
+	}
+	for key, value := range want {
+		if span.attributes[key] != value {
+			t.Errorf("span attribute %s = %v, want %v", key, span.attributes[key], value)
+		}
+	}
+	if _, ok := span.attributes[provider.AttributeResponseSize]; !ok {
+		t.Errorf("span attribute %s not set", provider.AttributeResponseSize)
+	}
+}
+
+func TestHTTPProvider_TracerResult(t *testing.T) {
+	response := `{"jsonrpc":"2.0","id":1,"result":"0x10"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	tracer := &testTracer{}
+	var number string
+	err := provider.DialHTTP(server.URL, provider.WithTracer(tracer)).CallResultContext(context.Background(), &number, "eth_blockNumber", []interface{}{})
+	if err != nil {
+		t.Fatalf("HTTPProvider.CallResultContext() error = %v", err)
+	}
+
+	span := tracer.spans[0]
+	if span.err != nil || !span.ended {
+		t.Errorf("span = %+v, want an ended span without error", span)
+	}
+	if got := span.attributes[provider.AttributeResponseSize]; got != int64(len(response)) {
+		t.Errorf("span attribute %s = %v, want %d", provider.AttributeResponseSize, got, len(response))
+	}
+}
+
+func TestHTTPProvider_TracerBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":"0x1"},{"jsonrpc":"2.0","id":2,"error":{"code":-32601,"message":"method not found"}}]`))
+	}))
+	defer server.Close()
+
+	tracer := &testTracer{}
+	batch := []provider.BatchElem{
+		{Method: "eth_blockNumber", Params: []interface{}{}},
+		{Method: "eth_unknown", Params: []interface{}{}},
+	}
+	if err := provider.DialHTTP(server.URL, provider.WithTracer(tracer)).BatchCallContext(context.Background(), batch); err != nil {
+		t.Fatalf("HTTPProvider.BatchCallContext() error = %v", err)
+	}
+
+	var got []string
+	for _, span := range tracer.spans {
+		if !span.ended {
+			t.Errorf("span %s not ended", span.name)
+		}
+		got = append(got, fmt.Sprintf("%s<%s %v %v", span.name, span.parent, span.attributes[provider.AttributeRequestID], span.attributes[provider.AttributeErrorCode]))
+	}
+	want := []string{"batch< <nil> <nil>", "eth_blockNumber<batch 1 <nil>", "eth_unknown<batch 2 -32601"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("spans = %v, want %v", got, want)
+	}
+	if size := tracer.spans[0].attributes[provider.AttributeBatchSize]; size != 2 {
+		t.Errorf("span attribute %s = %v, want 2", provider.AttributeBatchSize, size)
+	}
+}
+
+func TestTracingProvider(t *testing.T) {
+	endpoint, stop := startWebSocketNode(t)
+	defer stop()
+
+	ws, err := provider.DialWebSocket(endpoint)
+	if err != nil {
+		t.Fatalf("DialWebSocket() error = %v", err)
+	}
+	tracer := &testTracer{}
+	p := provider.NewTracingProvider(ws, tracer)
+	defer p.Close()
+
+	ctx, parent := tracer.Start(context.Background(), "handler")
+	if _, err := p.CallContext(ctx, "eth_blockNumber", []interface{}{}); err != nil {
+		t.Fatalf("TracingProvider.CallContext() error = %v", err)
+	}
+	if _, err := p.CallContext(ctx, "eth_unknown", []interface{}{}); err == nil {
+		t.Fatalf("TracingProvider.CallContext() error = nil, want the RPC error")
+	}
+	batch := []provider.BatchElem{
+		{Method: "eth_blockNumber", Params: []interface{}{}},
+		{Method: "eth_unknown", Params: []interface{}{}},
+	}
+	if err := p.BatchCallContext(ctx, batch); err != nil {
+		t.Fatalf("TracingProvider.BatchCallContext() error = %v", err)
+	}
+	sub, err := p.SubscribeContext(ctx, "newHeads")
+	if err != nil {
+		t.Fatalf("TracingProvider.SubscribeContext() error = %v", err)
+	}
+	sub.Unsubscribe()
+	parent.End()
+
+	var got []string
+	for _, span := range tracer.spans[1:] {
+		if !span.ended {
+			t.Errorf("span %s not ended", span.name)
+		}
+		got = append(got, fmt.Sprintf("%s<%s %v %v", span.name, span.parent, span.attributes[provider.AttributeRPCMethod], span.attributes[provider.AttributeErrorCode]))
+	}
+	want := []string{
+		"eth_blockNumber<handler eth_blockNumber <nil>",
+		"eth_unknown<handler eth_unknown -32601",
+		"batch<handler <nil> <nil>",
+		"eth_blockNumber<batch eth_blockNumber <nil>",
+		"eth_unknown<batch eth_unknown -32601",
+		"eth_subscribe<handler eth_subscribe <nil>",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("spans = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,104 @@
+package provider
+
+import "context"
+
+// TracingProvider wraps a provider and traces its calls, a span for each call, each
+// batch and each eth_subscribe call, named after the method.
+//
+// It works with any provider but only knows the method and the outcome of the calls,
+// an HTTPProvider dialed WithTracer also sets the request id, the endpoint and the sizes
+// of the messages. Do not use both, each call would be traced twice.
+type TracingProvider struct {
+	provider Provider
+	tracer   Tracer
+}
+
+// NewTracingProvider wraps p, tracing its calls with tracer.
+func NewTracingProvider(p Provider, tracer Tracer) *TracingProvider {
+	return &TracingProvider{
+		provider: p,
+		tracer:   tracer,
+	}
+}
+
+// Call makes a request with a specified method and parameters.
+func (t *TracingProvider) Call(method string, params interface{}) ([]byte, error) {
+	return t.CallContext(context.Background(), method, params)
+}
+
+// CallContext makes a request through the wrapped provider in a new span.
+func (t *TracingProvider) CallContext(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	ctx, span := t.startSpan(ctx, method)
+	reply, err := t.provider.CallContext(ctx, method, params)
+	span.end(err)
+
+	return reply, err
+}
+
+// CallResultContext decodes the result of the call through the wrapped provider in a new span.
+func (t *TracingProvider) CallResultContext(ctx context.Context, result interface{}, method string, params interface{}) error {
+	ctx, span := t.startSpan(ctx, method)
+	err := CallResult(ctx, t.provider, result, method, params)
+	span.end(err)
+
+	return err
+}
+
+// RawCall calls a method with a JSON encoded list of params.
+func (t *TracingProvider) RawCall(method string, args []interface{}) ([]byte, error) {
+	return t.CallContext(context.Background(), method, args)
+}
+
+// BatchCallContext sends the batch through the wrapped provider in a "batch" span,
+// with a child span for each request.
+func (t *TracingProvider) BatchCallContext(ctx context.Context, batch []BatchElem) error {
+	ctx, span := t.startSpan(ctx, "batch")
+	span.set(AttributeBatchSize, len(batch))
+	spans := make([]*callSpan, len(batch))
+	for i, elem := range batch {
+		_, spans[i] = t.startSpan(ctx, elem.Method)
+	}
+
+	err := BatchCallContext(ctx, t.provider, batch)
+	for i, elem := range batch {
+		if err != nil {
+			spans[i].end(err)
+			continue
+		}
+		spans[i].end(elem.Error)
+	}
+	span.end(err)
+
+	return err
+}
+
+// SubscribeContext subscribes through the wrapped provider, tracing the eth_subscribe
+// call. The notifications are not traced.
+func (t *TracingProvider) SubscribeContext(ctx context.Context, args ...interface{}) (*Subscription, error) {
+	subscriber, ok := t.provider.(Subscriber)
+	if !ok {
+		return nil, ErrSubscriptionNotSupported
+	}
+	ctx, span := t.startSpan(ctx, "eth_subscribe")
+	sub, err := subscriber.SubscribeContext(ctx, args...)
+	span.end(err)
+
+	return sub, err
+}
+
+// Close closes the wrapped provider.
+func (t *TracingProvider) Close() error {
+	return t.provider.Close()
+}
+
+// startSpan starts the span of a call, or of a batch, through the wrapped provider.
+func (t *TracingProvider) startSpan(ctx context.Context, name string) (context.Context, *callSpan) {
+	ctx, span := t.tracer.Start(ctx, name)
+	s := &callSpan{span: span}
+	s.set(AttributeRPCSystem, "jsonrpc")
+	if name != "batch" {
+		s.set(AttributeRPCMethod, name)
+	}
+
+	return ctx, s
+}
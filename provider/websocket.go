@@ -0,0 +1,81 @@
+package provider
+
+import (
+	"context"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketProvider sends JSON-RPC requests over a persistent WebSocket connection.
+//
+// Concurrent calls are multiplexed on the same connection and matched to their
+// responses by request ID.
+type WebSocketProvider struct {
+	stream *stream
+}
+
+// DialWebSocket connects to a WebSocket endpoint (ws:// or wss://) and returns a provider using it.
+func DialWebSocket(endpointWS string) (*WebSocketProvider, error) {
+	conn, _, err := websocket.DefaultDialer.Dial(endpointWS, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &WebSocketProvider{
+		stream: newStream(wsConn{conn}),
+	}
+
+	return p, nil
+}
+
+// Call makes a request with a specified method and parameters.
+func (c *WebSocketProvider) Call(method string, params interface{}) ([]byte, error) {
+	return c.stream.call(context.Background(), method, params, nil)
+}
+
+// CallContext makes a request with a specified method and parameters,
+// giving up on the response when the context is done.
+func (c *WebSocketProvider) CallContext(ctx context.Context, method string, params interface{}) ([]byte, error) {
+	return c.stream.call(ctx, method, params, nil)
+}
+
+// RawCall calls a method with a JSON encoded list of params.
+func (c *WebSocketProvider) RawCall(method string, args []interface{}) ([]byte, error) {
+	return c.stream.call(context.Background(), method, args, nil)
+}
+
+// Subscribe calls eth_subscribe with the given arguments and returns the subscription
+// delivering the notifications.
+//
+// args start with the subscription type ("newHeads", "logs", "newPendingTransactions")
+// followed by its parameters.
+func (c *WebSocketProvider) Subscribe(args ...interface{}) (*Subscription, error) {
+	return c.stream.subscribe(context.Background(), args)
+}
+
+// SubscribeContext is Subscribe giving up on the eth_subscribe response when the context is done.
+func (c *WebSocketProvider) SubscribeContext(ctx context.Context, args ...interface{}) (*Subscription, error) {
+	return c.stream.subscribe(ctx, args)
+}
+
+// Close closes the connection, failing the pending calls and ending all subscriptions.
+func (c *WebSocketProvider) Close() error {
+	return c.stream.close()
+}
+
+type wsConn struct {
+	conn *websocket.Conn
+}
+
+func (c wsConn) readMessage() ([]byte, error) {
+	_, message, err := c.conn.ReadMessage()
+	return message, err
+}
+
+func (c wsConn) writeMessage(message []byte) error {
+	return c.conn.WriteMessage(websocket.TextMessage, message)
+}
+
+func (c wsConn) close() error {
+	c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	return c.conn.Close()
+}
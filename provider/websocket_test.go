@@ -0,0 +1,212 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cleanunicorn/ethereum/provider"
+	"github.com/gorilla/websocket"
+)
+
+var _ provider.Provider = (*provider.WebSocketProvider)(nil)
+var _ provider.Subscriber = (*provider.WebSocketProvider)(nil)
+
+// startWebSocketNode starts a WebSocket JSON-RPC stand-in answering eth_blockNumber with the request id
+// and sending two notifications after each eth_subscribe.
+func startWebSocketNode(t *testing.T) (string, func()) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Could not upgrade connection, err: %s", err)
+			return
+		}
+		defer conn.Close()
+
+		var writeMu sync.Mutex
+		write := func(msg string) {
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			conn.WriteMessage(websocket.TextMessage, []byte(msg))
+		}
+
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var req struct {
+				ID     uint64        `json:"id"`
+				Method string        `json:"method"`
+				Params []interface{} `json:"params"`
+			}
+			if err := json.Unmarshal(message, &req); err != nil {
+				t.Errorf("Could not decode request, err: %s", err)
+				return
+			}
+
+			switch req.Method {
+			case "eth_blockNumber":
+				// Answer out of order to exercise the multiplexing
+				go func(id uint64) {
+					time.Sleep(time.Duration(10-id%10) * time.Millisecond)
+					write(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"0x%x"}`, id, id))
+				}(req.ID)
+			case "eth_subscribe":
+				write(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"0xabc"}`, req.ID))
+				write(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xabc","result":"0x1"}}`)
+				write(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xabc","result":"0x2"}}`)
+			case "eth_unsubscribe":
+				write(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":true}`, req.ID))
+			default:
+				write(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-32601,"message":"method not found"}}`, req.ID))
+			}
+		}
+	}))
+
+	return "ws" + strings.TrimPrefix(server.URL, "http"), server.Close
+}
+
+func TestWebSocketProvider_Call(t *testing.T) {
+	endpoint, stop := startWebSocketNode(t)
+	defer stop()
+
+	p, err := provider.DialWebSocket(endpoint)
+	if err != nil {
+		t.Fatalf("DialWebSocket() error = %v", err)
+	}
+	defer p.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reply, err := p.Call("eth_blockNumber", []interface{}{})
+			if err != nil {
+				t.Errorf("WebSocketProvider.Call() error = %v", err)
+				return
+			}
+			var resp struct {
+				ID     uint64 `json:"id"`
+				Result string `json:"result"`
+			}
+			json.Unmarshal(reply, &resp)
+			if resp.Result != fmt.Sprintf("0x%x", resp.ID) {
+				t.Errorf("WebSocketProvider.Call() = %s, response does not belong to request", reply)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := p.Call("eth_unknown", []interface{}{}); err == nil {
+		t.Errorf("WebSocketProvider.Call() expected error for unknown method")
+	}
+
+	p.Close()
+	if _, err := p.Call("eth_blockNumber", []interface{}{}); err != provider.ErrClosed {
+		t.Errorf("WebSocketProvider.Call() after Close error = %v, want %v", err, provider.ErrClosed)
+	}
+}
+
+func TestWebSocketProvider_Subscribe(t *testing.T) {
+	endpoint, stop := startWebSocketNode(t)
+	defer stop()
+
+	p, err := provider.DialWebSocket(endpoint)
+	if err != nil {
+		t.Fatalf("DialWebSocket() error = %v", err)
+	}
+	defer p.Close()
+
+	sub, err := p.Subscribe("newHeads")
+	if err != nil {
+		t.Fatalf("WebSocketProvider.Subscribe() error = %v", err)
+	}
+	if sub.ID != "0xabc" {
+		t.Errorf("Subscription.ID = %s, want 0xabc", sub.ID)
+	}
+
+	for _, want := range []string{`"0x1"`, `"0x2"`} {
+		select {
+		case got := <-sub.Notifications():
+			if string(got) != want {
+				t.Errorf("Subscription notification = %s, want %s", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for notification %s", want)
+		}
+	}
+
+	if err := sub.Unsubscribe(); err != nil {
+		t.Errorf("Subscription.Unsubscribe() error = %v", err)
+	}
+	if _, ok := <-sub.Notifications(); ok {
+		t.Errorf("Subscription notifications should be closed after Unsubscribe")
+	}
+}
+
+func TestWebSocketProvider_SubscribeCanceled(t *testing.T) {
+	unsubscribed := make(chan string, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Could not upgrade connection, err: %s", err)
+			return
+		}
+		defer conn.Close()
+
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var req struct {
+				ID     uint64        `json:"id"`
+				Method string        `json:"method"`
+				Params []interface{} `json:"params"`
+			}
+			json.Unmarshal(message, &req)
+
+			switch req.Method {
+			case "eth_subscribe":
+				// Answer once the caller gave up
+				time.Sleep(50 * time.Millisecond)
+				conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"0xabc"}`, req.ID)))
+			case "eth_unsubscribe":
+				unsubscribed <- fmt.Sprint(req.Params...)
+				conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":true}`, req.ID)))
+			}
+		}
+	}))
+	defer server.Close()
+
+	p, err := provider.DialWebSocket("ws" + strings.TrimPrefix(server.URL, "http"))
+	if err != nil {
+		t.Fatalf("DialWebSocket() error = %v", err)
+	}
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.SubscribeContext(ctx, "newHeads"); err != context.DeadlineExceeded {
+		t.Fatalf("WebSocketProvider.SubscribeContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	select {
+	case got := <-unsubscribed:
+		if got != "0xabc" {
+			t.Errorf("eth_unsubscribe params = %s, want 0xabc", got)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("The subscription created after the call was canceled was not unsubscribed")
+	}
+}
@@ -0,0 +1,353 @@
+package testnode
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Transaction validation errors, worded like geth so clients can classify them
+var (
+	errInvalidTransaction = errors.New("invalid transaction")
+	errNonceTooLow        = errors.New("nonce too low")
+	errNonceTooHigh       = errors.New("nonce too high")
+	errUnderpriced        = errors.New("replacement transaction underpriced")
+	errInsufficientFunds  = errors.New("insufficient funds for gas * price + value")
+	errIntrinsicGas       = errors.New("intrinsic gas too low")
+	errGasLimit           = errors.New("exceeds block gas limit")
+)
+
+// Gas costs of a transaction, without an EVM the transactions only pay the intrinsic gas
+const (
+	txGas                 = 21000
+	txGasContractCreation = 53000
+	txDataZeroGas         = 4
+	txDataNonZeroGas      = 16
+)
+
+type account struct {
+	balance *big.Int
+	nonce   uint64
+}
+
+// state is the accounts of the chain after a block, copied for each block.
+type state map[common.Address]*account
+
+func (s state) get(address common.Address) *account {
+	if a, ok := s[address]; ok {
+		return a
+	}
+	return &account{balance: new(big.Int)}
+}
+
+func (s state) copy() state {
+	c := make(state, len(s))
+	for address, a := range s {
+		c[address] = &account{balance: new(big.Int).Set(a.balance), nonce: a.nonce}
+	}
+	return c
+}
+
+type block struct {
+	number     uint64
+	hash       common.Hash
+	parentHash common.Hash
+	timestamp  uint64
+	gasLimit   uint64
+	gasUsed    uint64
+	txs        []*transaction
+	bloom      [256]byte
+	// state is the state after the block
+	state state
+}
+
+type transaction struct {
+	tx   *types.Transaction
+	from common.Address
+	// block is nil while the transaction is pending
+	block   *block
+	index   uint64
+	receipt *receipt
+}
+
+type receipt struct {
+	contractAddress   *common.Address
+	gasUsed           uint64
+	cumulativeGasUsed uint64
+	status            uint64
+	logs              []*log
+	bloom             [256]byte
+}
+
+type log struct {
+	address common.Address
+	topics  []common.Hash
+	data    []byte
+	// index is the position of the log in the block
+	index uint64
+	tx    *transaction
+}
+
+func newGenesis(alloc map[common.Address]*big.Int, gasLimit uint64) *block {
+	genesis := &block{
+		timestamp: uint64(time.Now().Unix()),
+		gasLimit:  gasLimit,
+		state:     make(state),
+	}
+	for address, balance := range alloc {
+		genesis.state[address] = &account{balance: new(big.Int).Set(balance)}
+	}
+	genesis.hash = genesis.computeHash()
+
+	return genesis
+}
+
+// computeHash identifies the block, it is not the hash of a real header.
+func (b *block) computeHash() common.Hash {
+	var header [16]byte
+	binary.BigEndian.PutUint64(header[:8], b.number)
+	binary.BigEndian.PutUint64(header[8:], b.timestamp)
+	data := [][]byte{b.parentHash.Bytes(), header[:]}
+	for _, t := range b.txs {
+		data = append(data, t.tx.Hash().Bytes())
+	}
+
+	return crypto.Keccak256Hash(data...)
+}
+
+func (n *Node) head() *block {
+	return n.blocks[len(n.blocks)-1]
+}
+
+// intrinsicGas returns the gas used by a transaction.
+func intrinsicGas(tx *types.Transaction) uint64 {
+	gas := uint64(txGas)
+	if tx.To() == nil {
+		gas = txGasContractCreation
+	}
+	for _, b := range tx.Data() {
+		if b == 0 {
+			gas += txDataZeroGas
+		} else {
+			gas += txDataNonZeroGas
+		}
+	}
+
+	return gas
+}
+
+// cost returns the maximum amount of ether spent by the transaction.
+func cost(tx *types.Transaction) *big.Int {
+	fee := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
+	return fee.Add(fee, tx.Value())
+}
+
+// sendRawTransaction validates the signed transaction and adds it to the pending ones.
+func (n *Node) sendRawTransaction(raw []byte) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(raw, tx); err != nil {
+		return common.Hash{}, errInvalidTransaction
+	}
+
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = n.signer
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	if tx.Gas() < intrinsicGas(tx) {
+		return common.Hash{}, errIntrinsicGas
+	}
+	if tx.Gas() > n.config.GasLimit {
+		return common.Hash{}, errGasLimit
+	}
+
+	// Check the transaction against the state including the pending transactions of the sender
+	sender := n.head().state.get(from)
+	if tx.Nonce() < sender.nonce {
+		return common.Hash{}, errNonceTooLow
+	}
+	next := sender.nonce
+	spent := new(big.Int)
+	replaced := -1
+	for i, t := range n.pending {
+		if t.from != from {
+			continue
+		}
+		if t.tx.Nonce() == tx.Nonce() {
+			if tx.GasPrice().Cmp(t.tx.GasPrice()) <= 0 {
+				return common.Hash{}, errUnderpriced
+			}
+			replaced = i
+			continue
+		}
+		next++
+		spent.Add(spent, cost(t.tx))
+	}
+	if replaced == -1 && tx.Nonce() > next {
+		return common.Hash{}, errNonceTooHigh
+	}
+	if spent.Add(spent, cost(tx)).Cmp(sender.balance) > 0 {
+		return common.Hash{}, errInsufficientFunds
+	}
+
+	t := &transaction{
+		tx:   tx,
+		from: from,
+	}
+	if replaced >= 0 {
+		delete(n.txs, n.pending[replaced].tx.Hash())
+		n.pending[replaced] = t
+	} else {
+		n.pending = append(n.pending, t)
+	}
+	n.txs[tx.Hash()] = t
+	// The pending transaction filters report the transaction even if it is mined before they are polled
+	for _, f := range n.filters {
+		if f.pending {
+			f.hashes = append(f.hashes, tx.Hash())
+		}
+	}
+
+	if !n.config.ManualMining {
+		n.mine()
+	}
+
+	return tx.Hash(), nil
+}
+
+// mine applies the pending transactions fitting in a block and appends it to the chain.
+func (n *Node) mine() *block {
+	parent := n.head()
+	b := &block{
+		number:     parent.number + 1,
+		parentHash: parent.hash,
+		timestamp:  uint64(time.Now().Unix()),
+		gasLimit:   n.config.GasLimit,
+		state:      parent.state.copy(),
+	}
+	if b.timestamp < parent.timestamp {
+		b.timestamp = parent.timestamp
+	}
+
+	var left []*transaction
+	for _, t := range n.pending {
+		if b.gasUsed+t.tx.Gas() > n.config.GasLimit {
+			left = append(left, t)
+			continue
+		}
+		t.block = b
+		t.index = uint64(len(b.txs))
+		b.txs = append(b.txs, t)
+		n.apply(b, t)
+	}
+	n.pending = left
+
+	b.hash = b.computeHash()
+	n.blocks = append(n.blocks, b)
+
+	return b
+}
+
+// apply executes the transaction on the state of the block and sets its receipt.
+func (n *Node) apply(b *block, t *transaction) {
+	tx := t.tx
+	gasUsed := intrinsicGas(tx)
+	sender := b.state.get(t.from)
+	sender.nonce++
+	sender.balance.Sub(sender.balance, new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(gasUsed)))
+	b.state[t.from] = sender
+
+	b.gasUsed += gasUsed
+	r := &receipt{
+		gasUsed:           gasUsed,
+		cumulativeGasUsed: b.gasUsed,
+		status:            1,
+	}
+	t.receipt = r
+
+	var to common.Address
+	if tx.To() == nil {
+		to = crypto.CreateAddress(t.from, tx.Nonce())
+		r.contractAddress = &to
+	} else {
+		to = *tx.To()
+	}
+
+	if contract, ok := n.contracts[to]; ok && tx.To() != nil {
+		_, logs, err := contract(Message{
+			From:  t.from,
+			To:    to,
+			Value: new(big.Int).Set(tx.Value()),
+			Data:  tx.Data(),
+		})
+		if err != nil {
+			r.status = 0
+			return
+		}
+		for _, l := range logs {
+			entry := &log{
+				address: to,
+				topics:  l.Topics,
+				data:    l.Data,
+				index:   uint64(countLogs(b.txs)),
+				tx:      t,
+			}
+			r.logs = append(r.logs, entry)
+			addToBloom(&r.bloom, entry)
+			addToBloom(&b.bloom, entry)
+		}
+	}
+
+	sender.balance.Sub(sender.balance, tx.Value())
+	recipient := b.state.get(to)
+	recipient.balance.Add(recipient.balance, tx.Value())
+	b.state[to] = recipient
+}
+
+// countLogs returns the number of logs emitted by the transactions.
+func countLogs(txs []*transaction) int {
+	count := 0
+	for _, t := range txs {
+		if t.receipt != nil {
+			count += len(t.receipt.logs)
+		}
+	}
+	return count
+}
+
+// call runs the contract at the address with the state of the latest block, without changing it.
+func (n *Node) call(msg Message) ([]byte, error) {
+	contract, ok := n.contracts[msg.To]
+	if !ok {
+		return []byte{}, nil
+	}
+
+	output, _, err := contract(msg)
+	return output, err
+}
+
+// addToBloom adds the address and the topics of the log to the bloom filter.
+func addToBloom(bloom *[256]byte, l *log) {
+	values := [][]byte{l.address.Bytes()}
+	for _, topic := range l.topics {
+		values = append(values, topic.Bytes())
+	}
+
+	for _, value := range values {
+		hash := crypto.Keccak256(value)
+		for i := 0; i < 6; i += 2 {
+			bit := (uint(hash[i])<<8 | uint(hash[i+1])) & 2047
+			bloom[255-bit/8] |= 1 << (bit % 8)
+		}
+	}
+}
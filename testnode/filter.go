@@ -0,0 +1,114 @@
+package testnode
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// filter is a filter installed with eth_newFilter, eth_newBlockFilter or eth_newPendingTransactionFilter.
+type filter struct {
+	// args is the criteria of a log filter, nil for the other filters
+	args    *filterArgs
+	pending bool
+	// next is the number of the first block not polled yet
+	next uint64
+	// hashes are the transactions sent since the pending transaction filter was last polled,
+	// mined or not
+	hashes []common.Hash
+}
+
+// errFilterNotFound is the error of geth for the unknown and expired filters
+var errFilterNotFound = &rpcError{Code: codeServerError, Message: "filter not found"}
+
+func (n *Node) installFilter(f *filter) string {
+	n.lastFilterID++
+	id := hexutil.EncodeUint64(n.lastFilterID)
+	n.filters[id] = f
+	return id
+}
+
+func (n *Node) filter(p params) (*filter, string, error) {
+	var id string
+	if err := p.decode(0, &id); err != nil {
+		return nil, "", err
+	}
+	f, ok := n.filters[id]
+	if !ok {
+		return nil, "", errFilterNotFound
+	}
+	return f, id, nil
+}
+
+func newFilter(n *Node, p params) (interface{}, error) {
+	var args filterArgs
+	if err := p.decode(0, &args); err != nil {
+		return nil, err
+	}
+	return n.installFilter(&filter{args: &args, next: n.head().number + 1}), nil
+}
+
+func newBlockFilter(n *Node, p params) (interface{}, error) {
+	return n.installFilter(&filter{next: n.head().number + 1}), nil
+}
+
+func newPendingTransactionFilter(n *Node, p params) (interface{}, error) {
+	return n.installFilter(&filter{pending: true}), nil
+}
+
+func getFilterChanges(n *Node, p params) (interface{}, error) {
+	f, _, err := n.filter(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.pending {
+		hashes := make([]string, len(f.hashes))
+		for i, hash := range f.hashes {
+			hashes[i] = hash.Hex()
+		}
+		f.hashes = nil
+		return hashes, nil
+	}
+
+	var blocks []*block
+	if f.next < uint64(len(n.blocks)) {
+		blocks = n.blocks[f.next:]
+	}
+	f.next = uint64(len(n.blocks))
+	if f.args != nil {
+		return matchLogs(blocks, *f.args)
+	}
+	hashes := make([]string, len(blocks))
+	for i, b := range blocks {
+		hashes[i] = b.hash.Hex()
+	}
+	return hashes, nil
+}
+
+func getFilterLogs(n *Node, p params) (interface{}, error) {
+	f, _, err := n.filter(p)
+	if err != nil {
+		return nil, err
+	}
+	if f.args == nil {
+		return nil, errFilterNotFound
+	}
+
+	blocks, err := n.filterBlocks(*f.args, 0)
+	if err != nil {
+		return nil, err
+	}
+	return matchLogs(blocks, *f.args)
+}
+
+func uninstallFilter(n *Node, p params) (interface{}, error) {
+	_, id, err := n.filter(p)
+	if err == errFilterNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	delete(n.filters, id)
+	return true, nil
+}
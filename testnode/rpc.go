@@ -0,0 +1,824 @@
+package testnode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeServerError    = -32000
+	codeLimitExceeded  = -32005
+	codeReverted       = 3
+)
+
+type request struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type response struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// ServeHTTP answers the JSON-RPC requests and batches of requests.
+func (n *Node) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		var requests []request
+		if err := json.Unmarshal(body, &requests); err != nil {
+			json.NewEncoder(w).Encode(errorResponse(nil, &rpcError{Code: codeParseError, Message: err.Error()}))
+			return
+		}
+		responses := make([]response, len(requests))
+		for i, req := range requests {
+			responses[i] = n.handle(req)
+		}
+		json.NewEncoder(w).Encode(responses)
+		return
+	}
+
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		json.NewEncoder(w).Encode(errorResponse(nil, &rpcError{Code: codeParseError, Message: err.Error()}))
+		return
+	}
+	json.NewEncoder(w).Encode(n.handle(req))
+}
+
+func errorResponse(id json.RawMessage, err *rpcError) response {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return response{Jsonrpc: "2.0", ID: id, Error: err}
+}
+
+// handle answers a request, holding the lock of the node.
+func (n *Node) handle(req request) response {
+	method, ok := methods[req.Method]
+	if !ok {
+		return errorResponse(req.ID, &rpcError{
+			Code:    codeMethodNotFound,
+			Message: fmt.Sprintf("the method %s does not exist/is not available", req.Method),
+		})
+	}
+
+	n.mu.Lock()
+	result, err := method(n, params(req.Params))
+	n.mu.Unlock()
+
+	if err != nil {
+		rpcErr, ok := err.(*rpcError)
+		if !ok {
+			rpcErr = &rpcError{Code: codeServerError, Message: err.Error()}
+		}
+		return errorResponse(req.ID, rpcErr)
+	}
+	if result == nil {
+		// Keep the null result of the objects not found
+		result = json.RawMessage("null")
+	}
+
+	return response{Jsonrpc: "2.0", ID: req.ID, Result: result}
+}
+
+// methods lists the supported JSON-RPC methods
+var methods = map[string]func(n *Node, p params) (interface{}, error){
+	"web3_clientVersion":                      clientVersion,
+	"net_version":                             netVersion,
+	"eth_chainId":                             chainID,
+	"eth_blockNumber":                         blockNumber,
+	"eth_gasPrice":                            gasPrice,
+	"eth_getBalance":                          getBalance,
+	"eth_getTransactionCount":                 getTransactionCount,
+	"eth_getCode":                             getCode,
+	"eth_sendRawTransaction":                  sendRawTransaction,
+	"eth_call":                                call,
+	"eth_estimateGas":                         estimateGas,
+	"eth_getBlockByNumber":                    getBlockByNumber,
+	"eth_getBlockByHash":                      getBlockByHash,
+	"eth_getTransactionByHash":                getTransactionByHash,
+	"eth_getTransactionReceipt":               getTransactionReceipt,
+	"eth_getBlockTransactionCountByNumber":    getBlockTransactionCountByNumber,
+	"eth_getBlockTransactionCountByHash":      getBlockTransactionCountByHash,
+	"eth_getTransactionByBlockNumberAndIndex": getTransactionByBlockNumberAndIndex,
+	"eth_getTransactionByBlockHashAndIndex":   getTransactionByBlockHashAndIndex,
+	"eth_getUncleCountByBlockNumber":          getUncleCountByBlockNumber,
+	"eth_getUncleCountByBlockHash":            getUncleCountByBlockHash,
+	"eth_getUncleByBlockNumberAndIndex":       getUncleByBlockNumberAndIndex,
+	"eth_getUncleByBlockHashAndIndex":         getUncleByBlockHashAndIndex,
+	"eth_getLogs":                             getLogs,
+	"eth_newFilter":                           newFilter,
+	"eth_newBlockFilter":                      newBlockFilter,
+	"eth_newPendingTransactionFilter":         newPendingTransactionFilter,
+	"eth_getFilterChanges":                    getFilterChanges,
+	"eth_getFilterLogs":                       getFilterLogs,
+	"eth_uninstallFilter":                     uninstallFilter,
+	"evm_mine":                                evmMine,
+}
+
+// params decodes the positional parameters of a request.
+type params []json.RawMessage
+
+func (p params) decode(i int, v interface{}) error {
+	if i >= len(p) {
+		return &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("missing value for required argument %d", i)}
+	}
+	if err := json.Unmarshal(p[i], v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid argument %d: %s", i, err)}
+	}
+	return nil
+}
+
+// block returns the block selected by the block number or tag at position i, "latest" if missing.
+func (p params) block(n *Node, i int) (*block, error) {
+	tag := "latest"
+	if i < len(p) {
+		if err := p.decode(i, &tag); err != nil {
+			return nil, err
+		}
+	}
+
+	switch tag {
+	case "latest", "pending", "safe", "finalized":
+		return n.head(), nil
+	case "earliest":
+		return n.blocks[0], nil
+	}
+	number, err := hexutil.DecodeUint64(tag)
+	if err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid block number %s", tag)}
+	}
+	if number >= uint64(len(n.blocks)) {
+		return nil, nil
+	}
+	return n.blocks[number], nil
+}
+
+func (p params) address(i int) (common.Address, error) {
+	var address string
+	if err := p.decode(i, &address); err != nil {
+		return common.Address{}, err
+	}
+	if !common.IsHexAddress(address) {
+		return common.Address{}, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid address %s", address)}
+	}
+	return common.HexToAddress(address), nil
+}
+
+func (p params) hash(i int) (common.Hash, error) {
+	var hash string
+	if err := p.decode(i, &hash); err != nil {
+		return common.Hash{}, err
+	}
+	return common.HexToHash(hash), nil
+}
+
+func (p params) index(i int) (uint64, error) {
+	var index string
+	if err := p.decode(i, &index); err != nil {
+		return 0, err
+	}
+	value, err := hexutil.DecodeUint64(index)
+	if err != nil {
+		return 0, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid index %s", index)}
+	}
+	return value, nil
+}
+
+func clientVersion(n *Node, p params) (interface{}, error) {
+	return "testnode", nil
+}
+
+func netVersion(n *Node, p params) (interface{}, error) {
+	return n.chainID.String(), nil
+}
+
+func chainID(n *Node, p params) (interface{}, error) {
+	return hexutil.EncodeBig(n.chainID), nil
+}
+
+func blockNumber(n *Node, p params) (interface{}, error) {
+	return hexutil.EncodeUint64(n.head().number), nil
+}
+
+func gasPrice(n *Node, p params) (interface{}, error) {
+	return hexutil.EncodeBig(n.config.GasPrice), nil
+}
+
+func getBalance(n *Node, p params) (interface{}, error) {
+	address, err := p.address(0)
+	if err != nil {
+		return nil, err
+	}
+	b, err := p.block(n, 1)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	return hexutil.EncodeBig(b.state.get(address).balance), nil
+}
+
+func getTransactionCount(n *Node, p params) (interface{}, error) {
+	address, err := p.address(0)
+	if err != nil {
+		return nil, err
+	}
+	b, err := p.block(n, 1)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	nonce := b.state.get(address).nonce
+
+	var tag string
+	p.decode(1, &tag)
+	if tag == "pending" {
+		for _, t := range n.pending {
+			if t.from == address {
+				nonce++
+			}
+		}
+	}
+	return hexutil.EncodeUint64(nonce), nil
+}
+
+func getCode(n *Node, p params) (interface{}, error) {
+	address, err := p.address(0)
+	if err != nil {
+		return nil, err
+	}
+	// Contracts are Go functions, report an invalid opcode as their code
+	if _, ok := n.contracts[address]; ok {
+		return "0xfe", nil
+	}
+	return "0x", nil
+}
+
+func sendRawTransaction(n *Node, p params) (interface{}, error) {
+	var raw string
+	if err := p.decode(0, &raw); err != nil {
+		return nil, err
+	}
+	data, err := hexutil.Decode(raw)
+	if err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid argument 0: %s", err)}
+	}
+
+	hash, err := n.sendRawTransaction(data)
+	if err != nil {
+		return nil, err
+	}
+	return hash.Hex(), nil
+}
+
+// callArgs is the message of eth_call and eth_estimateGas
+type callArgs struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Value string `json:"value"`
+	Data  string `json:"data"`
+	Input string `json:"input"`
+}
+
+func (args callArgs) message() (Message, error) {
+	msg := Message{
+		From:  common.HexToAddress(args.From),
+		To:    common.HexToAddress(args.To),
+		Value: new(big.Int),
+	}
+	if args.Value != "" {
+		value, err := hexutil.DecodeBig(args.Value)
+		if err != nil {
+			return Message{}, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid value %s", args.Value)}
+		}
+		msg.Value = value
+	}
+	data := args.Input
+	if data == "" {
+		data = args.Data
+	}
+	if data != "" {
+		decoded, err := hexutil.Decode(data)
+		if err != nil {
+			return Message{}, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid data %s", data)}
+		}
+		msg.Data = decoded
+	}
+
+	return msg, nil
+}
+
+func call(n *Node, p params) (interface{}, error) {
+	var args callArgs
+	if err := p.decode(0, &args); err != nil {
+		return nil, err
+	}
+	msg, err := args.message()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := n.call(msg)
+	if err != nil {
+		rpcErr := &rpcError{Code: codeReverted, Message: "execution reverted"}
+		if revert, ok := err.(*RevertError); ok {
+			rpcErr.Data = hexutil.Encode(revert.Data)
+		} else {
+			rpcErr.Message = "execution reverted: " + err.Error()
+		}
+		return nil, rpcErr
+	}
+	return hexutil.Encode(output), nil
+}
+
+func estimateGas(n *Node, p params) (interface{}, error) {
+	var args callArgs
+	if err := p.decode(0, &args); err != nil {
+		return nil, err
+	}
+	msg, err := args.message()
+	if err != nil {
+		return nil, err
+	}
+
+	gas := uint64(txGas)
+	if args.To == "" {
+		gas = txGasContractCreation
+	}
+	for _, b := range msg.Data {
+		if b == 0 {
+			gas += txDataZeroGas
+		} else {
+			gas += txDataNonZeroGas
+		}
+	}
+	return hexutil.EncodeUint64(gas), nil
+}
+
+func getBlockByNumber(n *Node, p params) (interface{}, error) {
+	b, err := p.block(n, 0)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	var full bool
+	if err := p.decode(1, &full); err != nil {
+		return nil, err
+	}
+	return marshalBlock(b, full), nil
+}
+
+func getBlockByHash(n *Node, p params) (interface{}, error) {
+	hash, err := p.hash(0)
+	if err != nil {
+		return nil, err
+	}
+	var full bool
+	if err := p.decode(1, &full); err != nil {
+		return nil, err
+	}
+	b := n.blockByHash(hash)
+	if b == nil {
+		return nil, nil
+	}
+	return marshalBlock(b, full), nil
+}
+
+func (n *Node) blockByHash(hash common.Hash) *block {
+	for _, b := range n.blocks {
+		if b.hash == hash {
+			return b
+		}
+	}
+	return nil
+}
+
+func getTransactionByHash(n *Node, p params) (interface{}, error) {
+	hash, err := p.hash(0)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := n.txs[hash]
+	if !ok {
+		return nil, nil
+	}
+	return marshalTransaction(t), nil
+}
+
+func getTransactionReceipt(n *Node, p params) (interface{}, error) {
+	hash, err := p.hash(0)
+	if err != nil {
+		return nil, err
+	}
+	t, ok := n.txs[hash]
+	if !ok || t.block == nil {
+		return nil, nil
+	}
+	return marshalReceipt(t), nil
+}
+
+func getBlockTransactionCountByNumber(n *Node, p params) (interface{}, error) {
+	b, err := p.block(n, 0)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	return hexutil.EncodeUint64(uint64(len(b.txs))), nil
+}
+
+func getBlockTransactionCountByHash(n *Node, p params) (interface{}, error) {
+	hash, err := p.hash(0)
+	if err != nil {
+		return nil, err
+	}
+	b := n.blockByHash(hash)
+	if b == nil {
+		return nil, nil
+	}
+	return hexutil.EncodeUint64(uint64(len(b.txs))), nil
+}
+
+func getTransactionByBlockNumberAndIndex(n *Node, p params) (interface{}, error) {
+	b, err := p.block(n, 0)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	return transactionAt(b, p)
+}
+
+func getTransactionByBlockHashAndIndex(n *Node, p params) (interface{}, error) {
+	hash, err := p.hash(0)
+	if err != nil {
+		return nil, err
+	}
+	b := n.blockByHash(hash)
+	if b == nil {
+		return nil, nil
+	}
+	return transactionAt(b, p)
+}
+
+func transactionAt(b *block, p params) (interface{}, error) {
+	index, err := p.index(1)
+	if err != nil {
+		return nil, err
+	}
+	if index >= uint64(len(b.txs)) {
+		return nil, nil
+	}
+	return marshalTransaction(b.txs[index]), nil
+}
+
+// The blocks are mined one at a time, they never have uncles
+
+func getUncleCountByBlockNumber(n *Node, p params) (interface{}, error) {
+	b, err := p.block(n, 0)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	return "0x0", nil
+}
+
+func getUncleCountByBlockHash(n *Node, p params) (interface{}, error) {
+	hash, err := p.hash(0)
+	if err != nil {
+		return nil, err
+	}
+	if n.blockByHash(hash) == nil {
+		return nil, nil
+	}
+	return "0x0", nil
+}
+
+func getUncleByBlockNumberAndIndex(n *Node, p params) (interface{}, error) {
+	if _, err := p.block(n, 0); err != nil {
+		return nil, err
+	}
+	_, err := p.index(1)
+	return nil, err
+}
+
+func getUncleByBlockHashAndIndex(n *Node, p params) (interface{}, error) {
+	if _, err := p.hash(0); err != nil {
+		return nil, err
+	}
+	_, err := p.index(1)
+	return nil, err
+}
+
+// filterArgs is the filter of eth_getLogs
+type filterArgs struct {
+	FromBlock string          `json:"fromBlock"`
+	ToBlock   string          `json:"toBlock"`
+	BlockHash string          `json:"blockHash"`
+	Address   json.RawMessage `json:"address"`
+	Topics    []interface{}   `json:"topics"`
+}
+
+func getLogs(n *Node, p params) (interface{}, error) {
+	var args filterArgs
+	if err := p.decode(0, &args); err != nil {
+		return nil, err
+	}
+
+	blocks, err := n.filterBlocks(args, n.config.MaxLogRange)
+	if err != nil {
+		return nil, err
+	}
+	return matchLogs(blocks, args)
+}
+
+// filterBlocks returns the blocks searched by a filter, failing if the filter spans
+// more than maxRange blocks unless maxRange is 0.
+func (n *Node) filterBlocks(args filterArgs, maxRange uint64) ([]*block, error) {
+	var blocks []*block
+	if args.BlockHash != "" {
+		if b := n.blockByHash(common.HexToHash(args.BlockHash)); b != nil {
+			blocks = append(blocks, b)
+		}
+		return blocks, nil
+	}
+
+	from, err := n.blockNumber(args.FromBlock)
+	if err != nil {
+		return nil, err
+	}
+	to, err := n.blockNumber(args.ToBlock)
+	if err != nil {
+		return nil, err
+	}
+	if maxRange > 0 && to >= from && to-from >= maxRange {
+		return nil, &rpcError{Code: codeLimitExceeded, Message: fmt.Sprintf("exceed maximum block range: %d", maxRange)}
+	}
+	for number := from; number <= to && number < uint64(len(n.blocks)); number++ {
+		blocks = append(blocks, n.blocks[number])
+	}
+	return blocks, nil
+}
+
+// matchLogs returns the logs of the blocks matching the addresses and the topics of a filter.
+func matchLogs(blocks []*block, args filterArgs) ([]interface{}, error) {
+	addresses, err := filterAddresses(args.Address)
+	if err != nil {
+		return nil, err
+	}
+	topics, err := filterTopics(args.Topics)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := []interface{}{}
+	for _, b := range blocks {
+		for _, t := range b.txs {
+			for _, l := range t.receipt.logs {
+				if matchLog(l, addresses, topics) {
+					logs = append(logs, marshalLog(l))
+				}
+			}
+		}
+	}
+	return logs, nil
+}
+
+// blockNumber resolves a block tag of a filter, "latest" if empty.
+func (n *Node) blockNumber(tag string) (uint64, error) {
+	switch tag {
+	case "", "latest", "pending", "safe", "finalized":
+		return n.head().number, nil
+	case "earliest":
+		return 0, nil
+	}
+	number, err := hexutil.DecodeUint64(tag)
+	if err != nil {
+		return 0, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid block number %s", tag)}
+	}
+	return number, nil
+}
+
+// filterAddresses decodes the address of a filter, a single address or a list.
+func filterAddresses(raw json.RawMessage) ([]common.Address, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		var single string
+		if err := json.Unmarshal(raw, &single); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "invalid address filter"}
+		}
+		list = []string{single}
+	}
+
+	addresses := make([]common.Address, len(list))
+	for i, address := range list {
+		addresses[i] = common.HexToAddress(address)
+	}
+	return addresses, nil
+}
+
+// filterTopics decodes the topics of a filter, each position is nil to match
+// any topic, a topic or a list of alternative topics.
+func filterTopics(raw []interface{}) ([][]common.Hash, error) {
+	topics := make([][]common.Hash, len(raw))
+	for i, position := range raw {
+		switch value := position.(type) {
+		case nil:
+		case string:
+			topics[i] = []common.Hash{common.HexToHash(value)}
+		case []interface{}:
+			for _, alternative := range value {
+				topic, ok := alternative.(string)
+				if !ok {
+					return nil, &rpcError{Code: codeInvalidParams, Message: "invalid topic filter"}
+				}
+				topics[i] = append(topics[i], common.HexToHash(topic))
+			}
+		default:
+			return nil, &rpcError{Code: codeInvalidParams, Message: "invalid topic filter"}
+		}
+	}
+	return topics, nil
+}
+
+func matchLog(l *log, addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 {
+		found := false
+		for _, address := range addresses {
+			if address == l.address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(topics) > len(l.topics) {
+		return false
+	}
+	for i, alternatives := range topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range alternatives {
+			if topic == l.topics[i] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func evmMine(n *Node, p params) (interface{}, error) {
+	n.mine()
+	return "0x0", nil
+}
+
+// emptyUncleHash is the hash of an empty list of uncles
+const emptyUncleHash = "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347"
+
+func marshalBlock(b *block, full bool) map[string]interface{} {
+	txs := make([]interface{}, len(b.txs))
+	for i, t := range b.txs {
+		if full {
+			txs[i] = marshalTransaction(t)
+		} else {
+			txs[i] = t.tx.Hash().Hex()
+		}
+	}
+
+	return map[string]interface{}{
+		"number":           hexutil.EncodeUint64(b.number),
+		"hash":             b.hash.Hex(),
+		"parentHash":       b.parentHash.Hex(),
+		"nonce":            "0x0000000000000000",
+		"mixHash":          common.Hash{}.Hex(),
+		"sha3Uncles":       emptyUncleHash,
+		"logsBloom":        hexutil.Encode(b.bloom[:]),
+		"transactionsRoot": common.Hash{}.Hex(),
+		"stateRoot":        common.Hash{}.Hex(),
+		"receiptsRoot":     common.Hash{}.Hex(),
+		"miner":            common.Address{}.Hex(),
+		"difficulty":       "0x0",
+		"totalDifficulty":  "0x0",
+		"extraData":        "0x",
+		"size":             "0x0",
+		"gasLimit":         hexutil.EncodeUint64(b.gasLimit),
+		"gasUsed":          hexutil.EncodeUint64(b.gasUsed),
+		"timestamp":        hexutil.EncodeUint64(b.timestamp),
+		"transactions":     txs,
+		"uncles":           []string{},
+	}
+}
+
+func marshalTransaction(t *transaction) map[string]interface{} {
+	v, r, s := t.tx.RawSignatureValues()
+	result := map[string]interface{}{
+		"hash":             t.tx.Hash().Hex(),
+		"nonce":            hexutil.EncodeUint64(t.tx.Nonce()),
+		"blockHash":        nil,
+		"blockNumber":      nil,
+		"transactionIndex": nil,
+		"from":             strings.ToLower(t.from.Hex()),
+		"to":               nil,
+		"value":            hexutil.EncodeBig(t.tx.Value()),
+		"gas":              hexutil.EncodeUint64(t.tx.Gas()),
+		"gasPrice":         hexutil.EncodeBig(t.tx.GasPrice()),
+		"input":            hexutil.Encode(t.tx.Data()),
+		"v":                hexutil.EncodeBig(v),
+		"r":                hexutil.EncodeBig(r),
+		"s":                hexutil.EncodeBig(s),
+	}
+	if t.tx.To() != nil {
+		result["to"] = strings.ToLower(t.tx.To().Hex())
+	}
+	if t.block != nil {
+		result["blockHash"] = t.block.hash.Hex()
+		result["blockNumber"] = hexutil.EncodeUint64(t.block.number)
+		result["transactionIndex"] = hexutil.EncodeUint64(t.index)
+	}
+	return result
+}
+
+func marshalReceipt(t *transaction) map[string]interface{} {
+	logs := make([]interface{}, len(t.receipt.logs))
+	for i, l := range t.receipt.logs {
+		logs[i] = marshalLog(l)
+	}
+
+	result := map[string]interface{}{
+		"transactionHash":   t.tx.Hash().Hex(),
+		"transactionIndex":  hexutil.EncodeUint64(t.index),
+		"blockHash":         t.block.hash.Hex(),
+		"blockNumber":       hexutil.EncodeUint64(t.block.number),
+		"from":              strings.ToLower(t.from.Hex()),
+		"to":                nil,
+		"contractAddress":   nil,
+		"gasUsed":           hexutil.EncodeUint64(t.receipt.gasUsed),
+		"cumulativeGasUsed": hexutil.EncodeUint64(t.receipt.cumulativeGasUsed),
+		"logs":              logs,
+		"logsBloom":         hexutil.Encode(t.receipt.bloom[:]),
+		"status":            hexutil.EncodeUint64(t.receipt.status),
+	}
+	if t.tx.To() != nil {
+		result["to"] = strings.ToLower(t.tx.To().Hex())
+	}
+	if t.receipt.contractAddress != nil {
+		result["contractAddress"] = strings.ToLower(t.receipt.contractAddress.Hex())
+	}
+	return result
+}
+
+func marshalLog(l *log) map[string]interface{} {
+	topics := make([]string, len(l.topics))
+	for i, topic := range l.topics {
+		topics[i] = topic.Hex()
+	}
+
+	return map[string]interface{}{
+		"address":          strings.ToLower(l.address.Hex()),
+		"topics":           topics,
+		"data":             hexutil.Encode(l.data),
+		"blockHash":        l.tx.block.hash.Hex(),
+		"blockNumber":      hexutil.EncodeUint64(l.tx.block.number),
+		"transactionHash":  l.tx.tx.Hash().Hex(),
+		"transactionIndex": hexutil.EncodeUint64(l.tx.index),
+		"logIndex":         hexutil.EncodeUint64(l.index),
+		"removed":          false,
+	}
+}
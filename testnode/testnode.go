@@ -0,0 +1,180 @@
+// Package testnode runs an in-process Ethereum JSON-RPC node for tests.
+//
+// The node keeps an in-memory chain starting from the genesis allocations: it accepts
+// the signed transactions sent with eth_sendRawTransaction, mines them into blocks and
+// serves the blocks, transactions, receipts, logs, filters and balances over HTTP.
+//
+// There is no EVM, transactions move ether between accounts. Contracts are stood in by
+// Go functions registered with SetContract, they produce the output and the logs.
+package testnode
+
+import (
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Default node parameters
+const (
+	DefaultChainID  = 1337
+	DefaultGasLimit = 8000000
+)
+
+// Config configures a Node.
+type Config struct {
+	// ChainID is the network id returned by net_version and eth_chainId, expected in the
+	// EIP155 signatures. DefaultChainID by default.
+	ChainID int64
+	// Alloc is the balance of the accounts in the genesis block
+	Alloc map[common.Address]*big.Int
+	// GasLimit is the gas limit of the blocks, DefaultGasLimit by default
+	GasLimit uint64
+	// GasPrice is returned by eth_gasPrice, 1 gwei by default
+	GasPrice *big.Int
+	// ManualMining keeps the transactions pending until Mine is called or evm_mine is
+	// received, otherwise a block is mined for each transaction.
+	ManualMining bool
+	// MaxLogRange fails the eth_getLogs queries spanning more blocks, like the hosted
+	// nodes do. Unlimited if 0.
+	MaxLogRange uint64
+	// Addr is the address to listen on, such as "127.0.0.1:8545". A random local port by default.
+	Addr string
+}
+
+// Message is a transaction or a call sent to a contract.
+type Message struct {
+	From  common.Address
+	To    common.Address
+	Value *big.Int
+	Data  []byte
+}
+
+// Log is an event emitted by a contract.
+type Log struct {
+	Topics []common.Hash
+	Data   []byte
+}
+
+// Contract stands in for the code deployed at an address. It is given the transactions
+// and the calls sent to the address and returns their output and the logs to emit.
+// An error reverts the transaction, a *RevertError sets the revert data.
+//
+// Contracts run while the node is locked, they must not call the node.
+type Contract func(msg Message) (output []byte, logs []Log, err error)
+
+// RevertError reverts a transaction with data, usually an encoded Error(string).
+type RevertError struct {
+	Data []byte
+}
+
+func (e *RevertError) Error() string {
+	return "execution reverted"
+}
+
+// Node is an Ethereum node serving an in-memory chain over HTTP.
+type Node struct {
+	// URL is the HTTP endpoint of the node
+	URL string
+
+	server  *httptest.Server
+	config  Config
+	chainID *big.Int
+	signer  types.Signer
+
+	mu        sync.Mutex
+	blocks    []*block
+	pending   []*transaction
+	txs       map[common.Hash]*transaction
+	contracts map[common.Address]Contract
+	filters   map[string]*filter
+	// lastFilterID is the id of the last filter installed
+	lastFilterID uint64
+}
+
+// Start starts a node serving a chain made of the genesis block.
+// Close it to stop the server.
+func Start(config Config) (*Node, error) {
+	if config.ChainID == 0 {
+		config.ChainID = DefaultChainID
+	}
+	if config.GasLimit == 0 {
+		config.GasLimit = DefaultGasLimit
+	}
+	if config.GasPrice == nil {
+		config.GasPrice = big.NewInt(1000000000)
+	}
+
+	n := &Node{
+		config:    config,
+		chainID:   big.NewInt(config.ChainID),
+		signer:    types.NewEIP155Signer(big.NewInt(config.ChainID)),
+		txs:       make(map[common.Hash]*transaction),
+		contracts: make(map[common.Address]Contract),
+		filters:   make(map[string]*filter),
+	}
+	n.blocks = []*block{newGenesis(config.Alloc, config.GasLimit)}
+
+	n.server = httptest.NewUnstartedServer(n)
+	if config.Addr != "" {
+		listener, err := net.Listen("tcp", config.Addr)
+		if err != nil {
+			return nil, err
+		}
+		n.server.Listener.Close()
+		n.server.Listener = listener
+	}
+	n.server.Start()
+	n.URL = n.server.URL
+
+	return n, nil
+}
+
+// Close stops the server.
+func (n *Node) Close() {
+	n.server.Close()
+}
+
+// SetContract makes contract handle the transactions and calls sent to address.
+func (n *Node) SetContract(address common.Address, contract Contract) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.contracts[address] = contract
+}
+
+// Mine mines a block with the pending transactions and returns its number.
+func (n *Node) Mine() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.mine().number
+}
+
+// BlockNumber returns the number of the latest block.
+func (n *Node) BlockNumber() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.head().number
+}
+
+// Balance returns the balance of the account in the latest block.
+func (n *Node) Balance(address common.Address) *big.Int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return new(big.Int).Set(n.head().state.get(address).balance)
+}
+
+// DropFilters uninstalls the filters, like a node restarting or expiring the filters
+// not polled for a while.
+func (n *Node) DropFilters() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.filters = make(map[string]*filter)
+}
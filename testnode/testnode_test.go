@@ -0,0 +1,365 @@
+package testnode_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cleanunicorn/ethereum/core"
+	"github.com/cleanunicorn/ethereum/provider"
+	"github.com/cleanunicorn/ethereum/testnode"
+	"github.com/cleanunicorn/ethereum/web3"
+	"github.com/cleanunicorn/ethereum/web3/account"
+	"github.com/cleanunicorn/ethereum/web3/eth"
+	"github.com/cleanunicorn/ethereum/web3/types"
+	"github.com/ethereum/go-ethereum/common"
+	gethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const testChainID = 99
+
+var (
+	ether   = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	alice   = mustAccount("09b2e5a4cec476e891c8b2aae556399953c271f769e22d17554030c7a58b8d88")
+	bob     = mustAccount("8fb1d9dcc5812a63339fa6fef45f204338a9a136be4afd522df5648790fe9cb5")
+	token   = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	topic   = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	revert  = errors.New("not enough")
+	noValue = big.NewInt(0)
+)
+
+func mustAccount(key string) account.Account {
+	a, err := account.FromHexKey(key)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+func startNode(t *testing.T, manualMining bool) (*testnode.Node, web3.Client) {
+	node, err := testnode.Start(testnode.Config{
+		ChainID: testChainID,
+		Alloc: map[common.Address]*big.Int{
+			common.HexToAddress(alice.Address()): new(big.Int).Mul(big.NewInt(100), ether),
+		},
+		ManualMining: manualMining,
+	})
+	if err != nil {
+		t.Fatalf("testnode.Start() error = %v", err)
+	}
+	// token emits a log for each transaction, and reverts the empty calls
+	node.SetContract(token, func(msg testnode.Message) ([]byte, []testnode.Log, error) {
+		if len(msg.Data) == 0 {
+			return nil, nil, revert
+		}
+		return msg.Data, []testnode.Log{{Topics: []common.Hash{topic}, Data: msg.Data}}, nil
+	})
+
+	return node, web3.NewClient(provider.DialHTTP(node.URL))
+}
+
+func send(t *testing.T, c web3.Client, from account.Account, nonce uint64, to common.Address, value *big.Int, gasPrice int64, data []byte) (string, error) {
+	tx, err := core.SignTx(core.CreateSigner(testChainID), from, nonce, to, value, 30000, big.NewInt(gasPrice), data)
+	if err != nil {
+		t.Fatalf("core.SignTx() error = %v", err)
+	}
+	txs := gethtypes.Transactions{tx}
+	return c.Eth.SendRawTransaction(fmt.Sprintf("0x%x", txs.GetRlp(0)))
+}
+
+func TestNode_Transfer(t *testing.T) {
+	node, c := startNode(t, false)
+	defer node.Close()
+
+	hash, err := send(t, c, alice, 0, common.HexToAddress(bob.Address()), ether, 1, nil)
+	if err != nil {
+		t.Fatalf("SendRawTransaction() error = %v", err)
+	}
+
+	receipt, err := c.Eth.GetTransactionReceipt(hash)
+	if err != nil {
+		t.Fatalf("GetTransactionReceipt() error = %v", err)
+	}
+	if receipt.Status != "0x1" || receipt.BlockNumber != "0x1" || receipt.GasUsed != "0x5208" {
+		t.Errorf("GetTransactionReceipt() = %+v, want a successful transaction in block 1 using 21000 gas", receipt)
+	}
+
+	balance, err := c.Eth.GetBalance(bob.Address(), "latest")
+	if err != nil || balance.Cmp(ether) != 0 {
+		t.Errorf("GetBalance(bob) = %v, %v, want %v", balance, err, ether)
+	}
+	want := new(big.Int).Mul(big.NewInt(99), ether)
+	want.Sub(want, big.NewInt(21000))
+	if balance := node.Balance(common.HexToAddress(alice.Address())); balance.Cmp(want) != 0 {
+		t.Errorf("Balance(alice) = %v, want %v", balance, want)
+	}
+	if balance, _ := c.Eth.GetBalance(bob.Address(), "0x0"); balance.Sign() != 0 {
+		t.Errorf("GetBalance(bob, 0x0) = %v, want 0", balance)
+	}
+	if nonce, _ := c.Eth.GetTransactionCount(alice.Address(), "latest"); nonce != 1 {
+		t.Errorf("GetTransactionCount(alice) = %d, want 1", nonce)
+	}
+
+	tx, err := c.Eth.GetTransactionByBlockNumberAndIndex("0x1", 0)
+	if err != nil || tx.Hash != hash || !strings.EqualFold(tx.From, alice.Address()) {
+		t.Errorf("GetTransactionByBlockNumberAndIndex() = %+v, %v, want %s from alice", tx, err, hash)
+	}
+	if _, err := c.Eth.GetTransactionByHash(common.Hash{}.Hex()); err != eth.ErrNotFound {
+		t.Errorf("GetTransactionByHash() error = %v, want %v", err, eth.ErrNotFound)
+	}
+
+	block, err := c.Eth.GetBlockByNumber("0x1", false)
+	if err != nil {
+		t.Fatalf("GetBlockByNumber() error = %v", err)
+	}
+	if len(block.TransactionHashes) != 1 || block.TransactionHashes[0] != hash {
+		t.Errorf("GetBlockByNumber() transactions = %v, want [%s]", block.TransactionHashes, hash)
+	}
+	byHash, err := c.Eth.GetBlockByHash(block.Hash, true)
+	if err != nil || byHash.Number != block.Number || len(byHash.Transactions) != 1 || byHash.Transactions[0].Hash != hash {
+		t.Errorf("GetBlockByHash() = %+v, %v, want block 1 with its transaction", byHash, err)
+	}
+	if _, err := c.Eth.GetBlockByNumber("0x2", false); err != eth.ErrNotFound {
+		t.Errorf("GetBlockByNumber(0x2) error = %v, want %v", err, eth.ErrNotFound)
+	}
+
+	if count, err := c.Eth.GetBlockTransactionCountByHash(block.Hash); err != nil || count != 1 {
+		t.Errorf("GetBlockTransactionCountByHash() = %d, %v, want 1", count, err)
+	}
+	if count, err := c.Eth.GetBlockTransactionCountByNumber("0x0"); err != nil || count != 0 {
+		t.Errorf("GetBlockTransactionCountByNumber(0x0) = %d, %v, want 0", count, err)
+	}
+	if count, err := c.Eth.GetUncleCountByBlockNumber("0x1"); err != nil || count != 0 {
+		t.Errorf("GetUncleCountByBlockNumber() = %d, %v, want 0", count, err)
+	}
+	if _, err := c.Eth.GetUncleCountByBlockHash(common.Hash{}.Hex()); err != eth.ErrNotFound {
+		t.Errorf("GetUncleCountByBlockHash() error = %v, want %v", err, eth.ErrNotFound)
+	}
+	if _, err := c.Eth.GetUncleByBlockHashAndIndex(block.Hash, 0); err != eth.ErrNotFound {
+		t.Errorf("GetUncleByBlockHashAndIndex() error = %v, want %v", err, eth.ErrNotFound)
+	}
+}
+
+func TestNode_InvalidTransactions(t *testing.T) {
+	node, c := startNode(t, true)
+	defer node.Close()
+
+	bobAddress := common.HexToAddress(bob.Address())
+	if _, err := send(t, c, alice, 0, bobAddress, ether, 2, nil); err != nil {
+		t.Fatalf("SendRawTransaction() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		from     account.Account
+		nonce    uint64
+		value    *big.Int
+		gasPrice int64
+		want     error
+	}{
+		{
+			name:     "Replacement with the same gas price",
+			from:     alice,
+			nonce:    0,
+			value:    noValue,
+			gasPrice: 2,
+			want:     provider.ErrReplacementUnderpriced,
+		},
+		{
+			name:     "Sender without ether",
+			from:     bob,
+			nonce:    0,
+			value:    noValue,
+			gasPrice: 1,
+			want:     provider.ErrInsufficientFunds,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := send(t, c, tt.from, tt.nonce, bobAddress, tt.value, tt.gasPrice, nil)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("SendRawTransaction() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if number := node.Mine(); number != 1 {
+		t.Errorf("Mine() = %d, want 1", number)
+	}
+	if _, err := send(t, c, alice, 0, bobAddress, ether, 3, nil); !errors.Is(err, provider.ErrNonceTooLow) {
+		t.Errorf("SendRawTransaction() error = %v, want %v", err, provider.ErrNonceTooLow)
+	}
+}
+
+func TestNode_Contract(t *testing.T) {
+	node, c := startNode(t, false)
+	defer node.Close()
+
+	if _, err := send(t, c, alice, 0, token, noValue, 1, []byte{0x01}); err != nil {
+		t.Fatalf("SendRawTransaction() error = %v", err)
+	}
+	reverted, err := send(t, c, alice, 1, token, noValue, 1, nil)
+	if err != nil {
+		t.Fatalf("SendRawTransaction() error = %v", err)
+	}
+	if receipt, _ := c.Eth.GetTransactionReceipt(reverted); receipt.Status != "0x0" || len(receipt.Logs) != 0 {
+		t.Errorf("GetTransactionReceipt() = %+v, want a reverted transaction without logs", receipt)
+	}
+
+	logs, err := c.Eth.GetLogs(types.FilterQuery{
+		FromBlock: "earliest",
+		Addresses: []string{token.Hex()},
+		Topics:    [][]string{{topic.Hex()}},
+	})
+	if err != nil {
+		t.Fatalf("GetLogs() error = %v", err)
+	}
+	if len(logs) != 1 || logs[0].BlockNumber != "0x1" || logs[0].Data != "0x01" {
+		t.Errorf("GetLogs() = %+v, want the log of block 1", logs)
+	}
+	if logs, _ := c.Eth.GetLogs(types.FilterQuery{FromBlock: "earliest", Topics: [][]string{{}, {topic.Hex()}}}); len(logs) != 0 {
+		t.Errorf("GetLogs() = %+v, want no log with a second topic", logs)
+	}
+
+	output, err := c.Eth.Call(types.CallMsg{To: token.Hex(), Data: []byte{0x02}}, "latest")
+	if err != nil || !bytes.Equal(output, []byte{0x02}) {
+		t.Errorf("Call() = %x, %v, want 02", output, err)
+	}
+	if _, err := c.Eth.Call(types.CallMsg{To: token.Hex()}, "latest"); !errors.Is(err, provider.ErrExecutionReverted) {
+		t.Errorf("Call() error = %v, want %v", err, provider.ErrExecutionReverted)
+	}
+	if gas, err := c.Eth.EstimateGas(types.CallMsg{From: alice.Address(), To: token.Hex(), Data: []byte{0x00, 0x01}}); err != nil || gas != 21020 {
+		t.Errorf("EstimateGas() = %d, %v, want 21020", gas, err)
+	}
+}
+
+func TestNode_MaxLogRange(t *testing.T) {
+	node, err := testnode.Start(testnode.Config{MaxLogRange: 2})
+	if err != nil {
+		t.Fatalf("testnode.Start() error = %v", err)
+	}
+	defer node.Close()
+	for i := 0; i < 4; i++ {
+		node.Mine()
+	}
+	c := web3.NewClient(provider.DialHTTP(node.URL))
+
+	if _, err := c.Provider.Call("eth_getLogs", []interface{}{map[string]string{"fromBlock": "0x0", "toBlock": "0x4"}}); !errors.Is(err, provider.ErrRangeTooLarge) {
+		t.Errorf("eth_getLogs error = %v, want %v", err, provider.ErrRangeTooLarge)
+	}
+	// The range is split by GetLogs
+	if logs, err := c.Eth.GetLogs(types.FilterQuery{FromBlock: "earliest"}); err != nil || len(logs) != 0 {
+		t.Errorf("GetLogs() = %v, %v, want no logs", logs, err)
+	}
+}
+
+func TestNode_Filters(t *testing.T) {
+	node, c := startNode(t, true)
+	defer node.Close()
+
+	logFilter, err := c.Eth.NewFilter(types.FilterQuery{FromBlock: "earliest", Addresses: []string{token.Hex()}})
+	if err != nil {
+		t.Fatalf("NewFilter() error = %v", err)
+	}
+	blockFilter, _ := c.Eth.NewBlockFilter()
+	pendingFilter, _ := c.Eth.NewPendingTransactionFilter()
+
+	hash, err := send(t, c, alice, 0, token, noValue, 1, []byte{0x01})
+	if err != nil {
+		t.Fatalf("SendRawTransaction() error = %v", err)
+	}
+	if changes, err := c.Eth.GetFilterChanges(pendingFilter); err != nil || len(changes.Hashes) != 1 || changes.Hashes[0] != hash {
+		t.Errorf("GetFilterChanges(pending) = %+v, %v, want [%s]", changes, err, hash)
+	}
+	node.Mine()
+
+	if changes, err := c.Eth.GetFilterChanges(logFilter); err != nil || len(changes.Logs) != 1 || changes.Logs[0].TransactionHash != hash {
+		t.Errorf("GetFilterChanges(logs) = %+v, %v, want the log of %s", changes, err, hash)
+	}
+	if changes, err := c.Eth.GetFilterChanges(logFilter); err != nil || len(changes.Logs) != 0 {
+		t.Errorf("GetFilterChanges(logs) = %+v, %v, want no new log", changes, err)
+	}
+	if logs, err := c.Eth.GetFilterLogs(logFilter); err != nil || len(logs) != 1 {
+		t.Errorf("GetFilterLogs() = %+v, %v, want 1 log", logs, err)
+	}
+	block, _ := c.Eth.GetBlockByNumber("latest", false)
+	if changes, err := c.Eth.GetFilterChanges(blockFilter); err != nil || len(changes.Hashes) != 1 || changes.Hashes[0] != block.Hash {
+		t.Errorf("GetFilterChanges(blocks) = %+v, %v, want [%s]", changes, err, block.Hash)
+	}
+
+	if ok, err := c.Eth.UninstallFilter(logFilter); !ok || err != nil {
+		t.Errorf("UninstallFilter() = %v, %v, want true", ok, err)
+	}
+	if _, err := c.Eth.GetFilterChanges(logFilter); !errors.Is(err, provider.ErrFilterNotFound) {
+		t.Errorf("GetFilterChanges() error = %v, want %v", err, provider.ErrFilterNotFound)
+	}
+}
+
+func TestNode_WatchBlocks(t *testing.T) {
+	node, c := startNode(t, true)
+	defer node.Close()
+
+	filter, err := c.Eth.WatchBlocks(10 * time.Millisecond)
+	if err != nil {
+		t.Fatalf("WatchBlocks() error = %v", err)
+	}
+	first := filter.ID()
+
+	node.Mine()
+	if changes := <-filter.Changes(); len(changes.Hashes) != 1 {
+		t.Errorf("Changes() = %+v, want block 1", changes)
+	}
+
+	// The node forgets the filter, it is installed again
+	node.DropFilters()
+	for filter.ID() == first {
+		time.Sleep(5 * time.Millisecond)
+	}
+	node.Mine()
+	if changes := <-filter.Changes(); len(changes.Hashes) != 1 {
+		t.Errorf("Changes() = %+v, want block 2", changes)
+	}
+
+	id := filter.ID()
+	if err := filter.Close(); err != nil {
+		t.Errorf("Filter.Close() error = %v", err)
+	}
+	if _, ok := <-filter.Changes(); ok {
+		t.Errorf("Changes() not closed")
+	}
+	if ok, _ := c.Eth.UninstallFilter(id); ok {
+		t.Errorf("UninstallFilter() = true, want the filter uninstalled by Close")
+	}
+}
+
+func TestNode_PendingTransactionFilterAutoMine(t *testing.T) {
+	node, c := startNode(t, false)
+	defer node.Close()
+
+	pendingFilter, err := c.Eth.NewPendingTransactionFilter()
+	if err != nil {
+		t.Fatalf("NewPendingTransactionFilter() error = %v", err)
+	}
+
+	// Both transactions are mined as soon as they are sent
+	var want []string
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		hash, err := send(t, c, alice, nonce, common.HexToAddress(bob.Address()), big.NewInt(1), 1, nil)
+		if err != nil {
+			t.Fatalf("SendRawTransaction() error = %v", err)
+		}
+		want = append(want, hash)
+	}
+
+	changes, err := c.Eth.GetFilterChanges(pendingFilter)
+	if err != nil || fmt.Sprint(changes.Hashes) != fmt.Sprint(want) {
+		t.Errorf("GetFilterChanges(pending) = %+v, %v, want %v", changes, err, want)
+	}
+	if changes, err := c.Eth.GetFilterChanges(pendingFilter); err != nil || len(changes.Hashes) != 0 {
+		t.Errorf("GetFilterChanges(pending) = %+v, %v, want no new transaction", changes, err)
+	}
+}
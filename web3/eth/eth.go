@@ -1,7 +1,10 @@
 package eth
 
 import (
+	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"strconv"
@@ -13,6 +16,9 @@ import (
 	"github.com/cleanunicorn/ethereum/web3/types"
 )
 
+// ErrNotFound is returned when the node does not know the requested transaction or block.
+var ErrNotFound = errors.New("not found")
+
 // Eth module
 type Eth struct {
 	provider provider.Provider
@@ -37,18 +43,18 @@ type ResponseEthGetTransactionCount struct {
 //
 // See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_gettransactioncount
 func (c Eth) GetTransactionCount(account string, block string) (uint64, error) {
-	reply, err := c.provider.Call("eth_getTransactionCount", []interface{}{account, block})
-	if err != nil {
-		return 0, err
-	}
+	return c.GetTransactionCountContext(context.Background(), account, block)
+}
 
-	var transactionCountReply ResponseEthGetTransactionCount
-	err = json.Unmarshal(reply, &transactionCountReply)
+// GetTransactionCountContext is GetTransactionCount giving up when the context is done.
+func (c Eth) GetTransactionCountContext(ctx context.Context, account string, block string) (uint64, error) {
+	var result string
+	err := provider.CallResult(ctx, c.provider, &result, "eth_getTransactionCount", []interface{}{account, block})
 	if err != nil {
 		return 0, err
 	}
 
-	count, err := strconv.ParseUint(transactionCountReply.Result, 0, 64)
+	count, err := strconv.ParseUint(result, 0, 64)
 	if err != nil {
 		return 0, err
 	}
@@ -72,18 +78,18 @@ type ResponseEthGetBalance struct {
 //
 // See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_gettransactioncount
 func (c Eth) GetBalance(account string, block string) (*big.Int, error) {
-	reply, err := c.provider.Call("eth_getBalance", []interface{}{account, block})
-	if err != nil {
-		return big.NewInt(0), err
-	}
+	return c.GetBalanceContext(context.Background(), account, block)
+}
 
-	var balanceReply ResponseEthGetBalance
-	err = json.Unmarshal(reply, &balanceReply)
+// GetBalanceContext is GetBalance giving up when the context is done.
+func (c Eth) GetBalanceContext(ctx context.Context, account string, block string) (*big.Int, error) {
+	var result string
+	err := provider.CallResult(ctx, c.provider, &result, "eth_getBalance", []interface{}{account, block})
 	if err != nil {
 		return big.NewInt(0), err
 	}
 
-	balance := helper.HexStrToBigInt(balanceReply.Result)
+	balance := helper.HexStrToBigInt(result)
 
 	return balance, nil
 }
@@ -96,6 +102,8 @@ type ResponseEthSendRawTransaction struct {
 }
 
 // ResponseEthSendRawTransactionError is the structure returned when an invalid signed transaction was sent
+//
+// Deprecated: SendRawTransaction returns the error of the node as a *provider.RPCError.
 type ResponseEthSendRawTransactionError struct {
 	Jsonrpc string `json:"jsonrpc"`
 	Error   struct {
@@ -107,9 +115,17 @@ type ResponseEthSendRawTransactionError struct {
 
 // SendRawTransaction send a signed transaction to the endpoint and returns the transaction hash.
 //
+// A rejected transaction returns a *provider.RPCError, which can be matched against
+// the common errors such as provider.ErrNonceTooLow with errors.Is.
+//
 // See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_sendrawtransaction
 func (c Eth) SendRawTransaction(signedTransaction string) (string, error) {
-	reply, err := c.provider.Call("eth_sendRawTransaction", []interface{}{signedTransaction})
+	return c.SendRawTransactionContext(context.Background(), signedTransaction)
+}
+
+// SendRawTransactionContext is SendRawTransaction giving up when the context is done.
+func (c Eth) SendRawTransactionContext(ctx context.Context, signedTransaction string) (string, error) {
+	reply, err := c.provider.CallContext(ctx, "eth_sendRawTransaction", []interface{}{signedTransaction})
 	if err != nil {
 		return "", err
 	}
@@ -121,17 +137,7 @@ func (c Eth) SendRawTransaction(signedTransaction string) (string, error) {
 	}
 
 	if strings.Compare(transactionHashReply.Result, "") == 0 {
-		var transactionError ResponseEthSendRawTransactionError
-		err := json.Unmarshal(reply, &transactionError)
-		if err != nil {
-			return "", err
-		}
-
-		if transactionError.Error.Code == 0 {
-			return "", fmt.Errorf("unknown error, got reply: %s", string(reply))
-		}
-
-		return "", fmt.Errorf("no transaction hash generated, got error code: %d message: %s", transactionError.Error.Code, transactionError.Error.Message)
+		return "", fmt.Errorf("unknown error, got reply: %s", string(reply))
 	}
 
 	return transactionHashReply.Result, nil
@@ -148,18 +154,18 @@ type ResponseEthBlockNumber struct {
 //
 // See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_blocknumber
 func (c Eth) BlockNumber() (*big.Int, error) {
-	reply, err := c.provider.Call("eth_blockNumber", []interface{}{})
-	if err != nil {
-		return big.NewInt(0), err
-	}
+	return c.BlockNumberContext(context.Background())
+}
 
-	var blockNumberReply ResponseEthBlockNumber
-	err = json.Unmarshal(reply, &blockNumberReply)
+// BlockNumberContext is BlockNumber giving up when the context is done.
+func (c Eth) BlockNumberContext(ctx context.Context) (*big.Int, error) {
+	var result string
+	err := provider.CallResult(ctx, c.provider, &result, "eth_blockNumber", []interface{}{})
 	if err != nil {
 		return big.NewInt(0), err
 	}
 
-	blockNumber := helper.HexStrToBigInt(blockNumberReply.Result)
+	blockNumber := helper.HexStrToBigInt(result)
 
 	return blockNumber, nil
 }
@@ -171,39 +177,64 @@ type ResponseEthGetBlockByNumber struct {
 	Result  types.Block `json:"result"`
 }
 
-// GetBlockByNumber returns the block with or without the transaction list included
+// GetBlockByNumber returns the block with or without the transaction list included,
+// or ErrNotFound if the block was not mined yet.
 //
 // See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getblockbynumber
 func (c Eth) GetBlockByNumber(blockNumberHex string, includeTransactions bool) (types.Block, error) {
-	reply, err := c.provider.Call("eth_getBlockByNumber", []interface{}{
-		blockNumberHex, includeTransactions,
-	})
-	if err != nil {
-		return types.Block{}, err
-	}
+	return c.GetBlockByNumberContext(context.Background(), blockNumberHex, includeTransactions)
+}
+
+// GetBlockByNumberContext is GetBlockByNumber giving up when the context is done.
+func (c Eth) GetBlockByNumberContext(ctx context.Context, blockNumberHex string, includeTransactions bool) (types.Block, error) {
+	return c.getBlock(ctx, "eth_getBlockByNumber", []interface{}{blockNumberHex, includeTransactions}, includeTransactions)
+}
+
+// GetBlockByHash returns the block with the hash, with or without the transaction list included,
+// or ErrNotFound if the node does not know it.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getblockbyhash
+func (c Eth) GetBlockByHash(blockHash string, includeTransactions bool) (types.Block, error) {
+	return c.GetBlockByHashContext(context.Background(), blockHash, includeTransactions)
+}
+
+// GetBlockByHashContext is GetBlockByHash giving up when the context is done.
+func (c Eth) GetBlockByHashContext(ctx context.Context, blockHash string, includeTransactions bool) (types.Block, error) {
+	return c.getBlock(ctx, "eth_getBlockByHash", []interface{}{blockHash, includeTransactions}, includeTransactions)
+}
 
-	var getBlockReply ResponseEthGetBlockByNumber
-	err = json.Unmarshal(reply, &getBlockReply)
+// getBlock calls a method returning a block and decodes its transaction list into the
+// transactions or their hashes. A null result is ErrNotFound.
+func (c Eth) getBlock(ctx context.Context, method string, params []interface{}, includeTransactions bool) (types.Block, error) {
+	var b *types.Block
+	err := provider.CallResult(ctx, c.provider, &b, method, params)
 	if err != nil {
 		return types.Block{}, err
 	}
+	if b == nil {
+		return types.Block{}, ErrNotFound
+	}
 
-	b := getBlockReply.Result
+	rawTransactions := b.RawTransactions
 	b.RawTransactions = json.RawMessage(`{}`)
 
+	// Uncles come without transactions
+	if len(rawTransactions) == 0 {
+		return *b, nil
+	}
 	if includeTransactions {
-		err := json.Unmarshal(getBlockReply.Result.RawTransactions, &b.Transactions)
+		err := json.Unmarshal(rawTransactions, &b.Transactions)
 		if err != nil {
 			return types.Block{}, err
 		}
 	} else {
-		err := json.Unmarshal(getBlockReply.Result.RawTransactions, &b.TransactionHashes)
+		err := json.Unmarshal(rawTransactions, &b.TransactionHashes)
 		if err != nil {
 			return types.Block{}, err
 		}
 	}
 
-	return b, nil
+	return *b, nil
 }
 
 // ResponseEthGetTransactionReceipt is the structure returned by https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_gettransactionreceipt
@@ -217,18 +248,257 @@ type ResponseEthGetTransactionReceipt struct {
 //
 // See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_gettransactionreceipt
 func (c Eth) GetTransactionReceipt(transactionHash string) (types.Receipt, error) {
-	reply, err := c.provider.Call("eth_getTransactionReceipt", []interface{}{
+	return c.GetTransactionReceiptContext(context.Background(), transactionHash)
+}
+
+// GetTransactionReceiptContext is GetTransactionReceipt giving up when the context is done.
+func (c Eth) GetTransactionReceiptContext(ctx context.Context, transactionHash string) (types.Receipt, error) {
+	var receipt types.Receipt
+	err := provider.CallResult(ctx, c.provider, &receipt, "eth_getTransactionReceipt", []interface{}{
 		transactionHash,
 	})
 	if err != nil {
 		return types.Receipt{}, err
 	}
 
-	var responseReceipt ResponseEthGetTransactionReceipt
-	err = json.Unmarshal(reply, &responseReceipt)
+	return receipt, nil
+}
+
+// GetTransactionReceipts returns the receipts of all the transactions, in the same order,
+// fetching them in a single batch when the provider supports it.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_gettransactionreceipt
+func (c Eth) GetTransactionReceipts(transactionHashes []string) ([]types.Receipt, error) {
+	return c.GetTransactionReceiptsContext(context.Background(), transactionHashes)
+}
+
+// GetTransactionReceiptsContext is GetTransactionReceipts giving up when the context is done.
+func (c Eth) GetTransactionReceiptsContext(ctx context.Context, transactionHashes []string) ([]types.Receipt, error) {
+	batch := make([]provider.BatchElem, len(transactionHashes))
+	for i, transactionHash := range transactionHashes {
+		batch[i] = provider.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Params: []interface{}{transactionHash},
+		}
+	}
+	if err := provider.BatchCallContext(ctx, c.provider, batch); err != nil {
+		return nil, err
+	}
+
+	receipts := make([]types.Receipt, len(batch))
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, fmt.Errorf("receipt of %s: %w", transactionHashes[i], elem.Error)
+		}
+
+		var responseReceipt ResponseEthGetTransactionReceipt
+		err := json.Unmarshal(elem.Result, &responseReceipt)
+		if err != nil {
+			return nil, err
+		}
+		receipts[i] = responseReceipt.Result
+	}
+
+	return receipts, nil
+}
+
+// Call executes the message at the block without creating a transaction and
+// returns its output, such as the values returned by a contract function.
+//
+// A reverted call returns a *provider.RPCError matching provider.ErrExecutionReverted,
+// its RevertReason method decodes the reason.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_call
+func (c Eth) Call(msg types.CallMsg, block string) ([]byte, error) {
+	return c.CallContext(context.Background(), msg, block)
+}
+
+// CallContext is Call giving up when the context is done.
+func (c Eth) CallContext(ctx context.Context, msg types.CallMsg, block string) ([]byte, error) {
+	return c.CallWithOverridesContext(ctx, msg, block, nil)
+}
+
+// CallWithOverrides is Call on top of a state replaced for the accounts of overrides,
+// for example to simulate a balance or contract code.
+func (c Eth) CallWithOverrides(msg types.CallMsg, block string, overrides types.StateOverride) ([]byte, error) {
+	return c.CallWithOverridesContext(context.Background(), msg, block, overrides)
+}
+
+// CallWithOverridesContext is CallWithOverrides giving up when the context is done.
+func (c Eth) CallWithOverridesContext(ctx context.Context, msg types.CallMsg, block string, overrides types.StateOverride) ([]byte, error) {
+	params := []interface{}{msg, block}
+	if len(overrides) > 0 {
+		params = append(params, overrides)
+	}
+
+	var result string
+	err := provider.CallResult(ctx, c.provider, &result, "eth_call", params)
 	if err != nil {
-		return types.Receipt{}, err
+		return nil, err
 	}
 
-	return responseReceipt.Result, nil
+	return hex.DecodeString(helper.Trim0x(result))
+}
+
+// EstimateGas returns the gas the message needs to be mined, estimated against the pending block.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_estimategas
+func (c Eth) EstimateGas(msg types.CallMsg) (uint64, error) {
+	return c.EstimateGasContext(context.Background(), msg)
+}
+
+// EstimateGasContext is EstimateGas giving up when the context is done.
+func (c Eth) EstimateGasContext(ctx context.Context, msg types.CallMsg) (uint64, error) {
+	var result string
+	err := provider.CallResult(ctx, c.provider, &result, "eth_estimateGas", []interface{}{msg})
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseUint(result, 0, 64)
+}
+
+// GetTransactionByHash returns the transaction with the hash, pending or mined,
+// or ErrNotFound if the node does not know it.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_gettransactionbyhash
+func (c Eth) GetTransactionByHash(transactionHash string) (types.Transaction, error) {
+	return c.GetTransactionByHashContext(context.Background(), transactionHash)
+}
+
+// GetTransactionByHashContext is GetTransactionByHash giving up when the context is done.
+func (c Eth) GetTransactionByHashContext(ctx context.Context, transactionHash string) (types.Transaction, error) {
+	return c.getTransaction(ctx, "eth_getTransactionByHash", []interface{}{transactionHash})
+}
+
+// GetTransactionByBlockHashAndIndex returns the transaction at the index of the block with the hash,
+// or ErrNotFound if there is none.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_gettransactionbyblockhashandindex
+func (c Eth) GetTransactionByBlockHashAndIndex(blockHash string, index uint64) (types.Transaction, error) {
+	return c.GetTransactionByBlockHashAndIndexContext(context.Background(), blockHash, index)
+}
+
+// GetTransactionByBlockHashAndIndexContext is GetTransactionByBlockHashAndIndex giving up when the context is done.
+func (c Eth) GetTransactionByBlockHashAndIndexContext(ctx context.Context, blockHash string, index uint64) (types.Transaction, error) {
+	return c.getTransaction(ctx, "eth_getTransactionByBlockHashAndIndex", []interface{}{blockHash, fmt.Sprintf("0x%x", index)})
+}
+
+// GetTransactionByBlockNumberAndIndex returns the transaction at the index of the block,
+// or ErrNotFound if there is none.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_gettransactionbyblocknumberandindex
+func (c Eth) GetTransactionByBlockNumberAndIndex(blockNumberHex string, index uint64) (types.Transaction, error) {
+	return c.GetTransactionByBlockNumberAndIndexContext(context.Background(), blockNumberHex, index)
+}
+
+// GetTransactionByBlockNumberAndIndexContext is GetTransactionByBlockNumberAndIndex giving up when the context is done.
+func (c Eth) GetTransactionByBlockNumberAndIndexContext(ctx context.Context, blockNumberHex string, index uint64) (types.Transaction, error) {
+	return c.getTransaction(ctx, "eth_getTransactionByBlockNumberAndIndex", []interface{}{blockNumberHex, fmt.Sprintf("0x%x", index)})
+}
+
+// getTransaction calls a method returning a transaction, a null result being ErrNotFound.
+func (c Eth) getTransaction(ctx context.Context, method string, params []interface{}) (types.Transaction, error) {
+	var transaction *types.Transaction
+	err := provider.CallResult(ctx, c.provider, &transaction, method, params)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	if transaction == nil {
+		return types.Transaction{}, ErrNotFound
+	}
+
+	return *transaction, nil
+}
+
+// GetBlockTransactionCountByHash returns the number of transactions in the block with the hash,
+// or ErrNotFound if the node does not know it.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getblocktransactioncountbyhash
+func (c Eth) GetBlockTransactionCountByHash(blockHash string) (uint64, error) {
+	return c.GetBlockTransactionCountByHashContext(context.Background(), blockHash)
+}
+
+// GetBlockTransactionCountByHashContext is GetBlockTransactionCountByHash giving up when the context is done.
+func (c Eth) GetBlockTransactionCountByHashContext(ctx context.Context, blockHash string) (uint64, error) {
+	return c.getCount(ctx, "eth_getBlockTransactionCountByHash", []interface{}{blockHash})
+}
+
+// GetBlockTransactionCountByNumber returns the number of transactions in the block,
+// or ErrNotFound if the node does not know it.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getblocktransactioncountbynumber
+func (c Eth) GetBlockTransactionCountByNumber(blockNumberHex string) (uint64, error) {
+	return c.GetBlockTransactionCountByNumberContext(context.Background(), blockNumberHex)
+}
+
+// GetBlockTransactionCountByNumberContext is GetBlockTransactionCountByNumber giving up when the context is done.
+func (c Eth) GetBlockTransactionCountByNumberContext(ctx context.Context, blockNumberHex string) (uint64, error) {
+	return c.getCount(ctx, "eth_getBlockTransactionCountByNumber", []interface{}{blockNumberHex})
+}
+
+// GetUncleCountByBlockHash returns the number of uncles of the block with the hash,
+// or ErrNotFound if the node does not know it.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getunclecountbyblockhash
+func (c Eth) GetUncleCountByBlockHash(blockHash string) (uint64, error) {
+	return c.GetUncleCountByBlockHashContext(context.Background(), blockHash)
+}
+
+// GetUncleCountByBlockHashContext is GetUncleCountByBlockHash giving up when the context is done.
+func (c Eth) GetUncleCountByBlockHashContext(ctx context.Context, blockHash string) (uint64, error) {
+	return c.getCount(ctx, "eth_getUncleCountByBlockHash", []interface{}{blockHash})
+}
+
+// GetUncleCountByBlockNumber returns the number of uncles of the block,
+// or ErrNotFound if the node does not know it.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getunclecountbyblocknumber
+func (c Eth) GetUncleCountByBlockNumber(blockNumberHex string) (uint64, error) {
+	return c.GetUncleCountByBlockNumberContext(context.Background(), blockNumberHex)
+}
+
+// GetUncleCountByBlockNumberContext is GetUncleCountByBlockNumber giving up when the context is done.
+func (c Eth) GetUncleCountByBlockNumberContext(ctx context.Context, blockNumberHex string) (uint64, error) {
+	return c.getCount(ctx, "eth_getUncleCountByBlockNumber", []interface{}{blockNumberHex})
+}
+
+// getCount calls a method returning a quantity, a null result being ErrNotFound.
+func (c Eth) getCount(ctx context.Context, method string, params []interface{}) (uint64, error) {
+	var count *string
+	err := provider.CallResult(ctx, c.provider, &count, method, params)
+	if err != nil {
+		return 0, err
+	}
+	if count == nil {
+		return 0, ErrNotFound
+	}
+
+	return strconv.ParseUint(*count, 0, 64)
+}
+
+// GetUncleByBlockHashAndIndex returns the uncle at the index of the block with the hash,
+// or ErrNotFound if there is none. Uncles have no transactions.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getunclebyblockhashandindex
+func (c Eth) GetUncleByBlockHashAndIndex(blockHash string, index uint64) (types.Block, error) {
+	return c.GetUncleByBlockHashAndIndexContext(context.Background(), blockHash, index)
+}
+
+// GetUncleByBlockHashAndIndexContext is GetUncleByBlockHashAndIndex giving up when the context is done.
+func (c Eth) GetUncleByBlockHashAndIndexContext(ctx context.Context, blockHash string, index uint64) (types.Block, error) {
+	return c.getBlock(ctx, "eth_getUncleByBlockHashAndIndex", []interface{}{blockHash, fmt.Sprintf("0x%x", index)}, false)
+}
+
+// GetUncleByBlockNumberAndIndex returns the uncle at the index of the block,
+// or ErrNotFound if there is none. Uncles have no transactions.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getunclebyblocknumberandindex
+func (c Eth) GetUncleByBlockNumberAndIndex(blockNumberHex string, index uint64) (types.Block, error) {
+	return c.GetUncleByBlockNumberAndIndexContext(context.Background(), blockNumberHex, index)
+}
+
+// GetUncleByBlockNumberAndIndexContext is GetUncleByBlockNumberAndIndex giving up when the context is done.
+func (c Eth) GetUncleByBlockNumberAndIndexContext(ctx context.Context, blockNumberHex string, index uint64) (types.Block, error) {
+	return c.getBlock(ctx, "eth_getUncleByBlockNumberAndIndex", []interface{}{blockNumberHex, fmt.Sprintf("0x%x", index)}, false)
 }
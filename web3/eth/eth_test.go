@@ -0,0 +1,257 @@
+package eth_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/cleanunicorn/ethereum/provider"
+	"github.com/cleanunicorn/ethereum/web3/eth"
+	"github.com/cleanunicorn/ethereum/web3/types"
+)
+
+const emptyAccount = "0x00000000000000000000000000000000000000ff"
+const zeroAccount = "0x0000000000000000000000000000000000000000"
+const account0 = "0xbd1e71ca74e8665718be94189a9e9f8ea07087d1"
+const account1 = "0xc5bda996ac2d16ee24e0c9f69e44e12f79ddeff3"
+
+func TestEth_GetTransactionReceipts_Error(t *testing.T) {
+	m := provider.NewMockProvider()
+	m.Expect("eth_getTransactionReceipt", []interface{}{"0x1"}).Return(map[string]string{"transactionHash": "0x1"})
+	m.Expect("eth_getTransactionReceipt", []interface{}{"0x2"}).ReturnError(-32000, "execution reverted")
+
+	_, err := eth.NewEth(m).GetTransactionReceipts([]string{"0x1", "0x2"})
+	var rpcErr *provider.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != -32000 {
+		t.Errorf("Eth.GetTransactionReceipts() error = %v, want the *provider.RPCError of the second receipt", err)
+	}
+	if !errors.Is(err, provider.ErrExecutionReverted) {
+		t.Errorf("Eth.GetTransactionReceipts() error = %v, want it to match provider.ErrExecutionReverted", err)
+	}
+	if err := m.Verify(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEth_GetBalance(t *testing.T) {
+	tests := []struct {
+		name   string
+		result string
+		want   string
+	}{
+		{
+			name:   "Zero",
+			result: "0x0",
+			want:   "0",
+		},
+		{
+			name:   "100 ether",
+			result: "0x56bc75e2d63100000",
+			want:   "100000000000000000000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := provider.NewMockProvider()
+			m.Expect("eth_getBalance", []interface{}{"0xff", "latest"}).Return(tt.result)
+
+			got, err := eth.NewEth(m).GetBalance("0xff", "latest")
+			if err != nil {
+				t.Fatalf("Eth.GetBalance() error = %v", err)
+			}
+			want, _ := new(big.Int).SetString(tt.want, 10)
+			if got.Cmp(want) != 0 {
+				t.Errorf("Eth.GetBalance() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEth_Call(t *testing.T) {
+	nonce := uint64(1)
+	tests := []struct {
+		name      string
+		msg       types.CallMsg
+		overrides types.StateOverride
+		params    string
+		result    string
+		want      []byte
+	}{
+		{
+			name:   "Contract call",
+			msg:    types.CallMsg{To: emptyAccount, Data: []byte{0x70, 0xa0, 0x82, 0x31}},
+			params: `[{"to":"` + emptyAccount + `","data":"0x70a08231"},"latest"]`,
+			result: "0x000000000000000000000000000000000000000000000000000000000000002a",
+			want:   append(make([]byte, 31), 0x2a),
+		},
+		{
+			name: "All the fields",
+			msg: types.CallMsg{
+				From:                 account0,
+				To:                   emptyAccount,
+				Gas:                  21000,
+				MaxFeePerGas:         big.NewInt(2000000000),
+				MaxPriorityFeePerGas: big.NewInt(1000000000),
+				Value:                big.NewInt(16),
+				AccessList:           types.AccessList{{Address: emptyAccount, StorageKeys: []string{}}},
+			},
+			params: `[{"from":"` + account0 + `","to":"` + emptyAccount + `","gas":"0x5208","maxFeePerGas":"0x77359400",` +
+				`"maxPriorityFeePerGas":"0x3b9aca00","value":"0x10","accessList":[{"address":"` + emptyAccount + `","storageKeys":[]}]},"latest"]`,
+			result: "0x",
+			want:   []byte{},
+		},
+		{
+			name: "State overrides",
+			msg:  types.CallMsg{To: emptyAccount},
+			overrides: types.StateOverride{
+				emptyAccount: {Nonce: &nonce, Code: []byte{0x00}, Balance: big.NewInt(1), StateDiff: map[string]string{"0x00": "0x01"}},
+			},
+			params: `[{"to":"` + emptyAccount + `"},"latest",{"` + emptyAccount + `":{"nonce":"0x1","code":"0x00","balance":"0x1","stateDiff":{"0x00":"0x01"}}}]`,
+			result: "0x01",
+			want:   []byte{0x01},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := provider.NewMockProvider()
+			m.Expect("eth_call", json.RawMessage(tt.params)).Return(tt.result)
+			e := eth.NewEth(m)
+
+			got, err := e.CallWithOverrides(tt.msg, "latest", tt.overrides)
+			if err != nil {
+				t.Fatalf("Eth.CallWithOverrides() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eth.CallWithOverrides() = %x, want %x", got, tt.want)
+			}
+			if err := m.Verify(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestEth_EstimateGas(t *testing.T) {
+	m := provider.NewMockProvider()
+	m.Expect("eth_estimateGas", json.RawMessage(`[{"from":"`+account0+`","to":"`+emptyAccount+`","value":"0x1"}]`)).Return("0x5208")
+	e := eth.NewEth(m)
+
+	got, err := e.EstimateGas(types.CallMsg{From: account0, To: emptyAccount, Value: big.NewInt(1)})
+	if err != nil || got != 21000 {
+		t.Errorf("Eth.EstimateGas() = %d, %v, want 21000", got, err)
+	}
+	if err := m.Verify(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEth_GetTransaction(t *testing.T) {
+	const hash = "0x4c65570f9ceab8a0a575af2f500b83c7d8077d595e42dff4c1f90e53b05c9ae8"
+	const blockHash = "0x3d6122660cc824376f11ee842f83addc3525e2dd6756b9bcf0affa6aa88cf741"
+	transaction := types.Transaction{Hash: hash, BlockHash: blockHash, BlockNumber: "0x1", TransactionIndex: "0x2"}
+
+	tests := []struct {
+		name    string
+		method  string
+		params  []interface{}
+		get     func(e eth.Eth) (types.Transaction, error)
+		result  interface{}
+		want    types.Transaction
+		wantErr error
+	}{
+		{
+			name:   "By hash",
+			method: "eth_getTransactionByHash",
+			params: []interface{}{hash},
+			get:    func(e eth.Eth) (types.Transaction, error) { return e.GetTransactionByHash(hash) },
+			result: transaction,
+			want:   transaction,
+		},
+		{
+			name:    "Unknown hash",
+			method:  "eth_getTransactionByHash",
+			params:  []interface{}{hash},
+			get:     func(e eth.Eth) (types.Transaction, error) { return e.GetTransactionByHash(hash) },
+			result:  nil,
+			wantErr: eth.ErrNotFound,
+		},
+		{
+			name:   "By block hash and index",
+			method: "eth_getTransactionByBlockHashAndIndex",
+			params: []interface{}{blockHash, "0x2"},
+			get: func(e eth.Eth) (types.Transaction, error) {
+				return e.GetTransactionByBlockHashAndIndex(blockHash, 2)
+			},
+			result: transaction,
+			want:   transaction,
+		},
+		{
+			name:   "By block number and index",
+			method: "eth_getTransactionByBlockNumberAndIndex",
+			params: []interface{}{"0x1", "0x2"},
+			get: func(e eth.Eth) (types.Transaction, error) {
+				return e.GetTransactionByBlockNumberAndIndex("0x1", 2)
+			},
+			result: transaction,
+			want:   transaction,
+		},
+		{
+			name:   "Index out of the block",
+			method: "eth_getTransactionByBlockNumberAndIndex",
+			params: []interface{}{"0x1", "0x10"},
+			get: func(e eth.Eth) (types.Transaction, error) {
+				return e.GetTransactionByBlockNumberAndIndex("0x1", 16)
+			},
+			result:  nil,
+			wantErr: eth.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := provider.NewMockProvider()
+			m.Expect(tt.method, tt.params).Return(tt.result)
+
+			got, err := tt.get(eth.NewEth(m))
+			if err != tt.wantErr {
+				t.Fatalf("%s error = %v, want %v", tt.method, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s = %+v, want %+v", tt.method, got, tt.want)
+			}
+			if err := m.Verify(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestEth_GetUncle(t *testing.T) {
+	const blockHash = "0x3d6122660cc824376f11ee842f83addc3525e2dd6756b9bcf0affa6aa88cf741"
+	uncle := map[string]interface{}{
+		"hash":   "0x5cd50096dbb856a6d1befa6de8f9c20decb299f375154427d90761dc0b101109",
+		"number": "0x3f",
+		"uncles": []string{},
+	}
+
+	m := provider.NewMockProvider()
+	m.Expect("eth_getUncleCountByBlockHash", []interface{}{blockHash}).Return("0x1")
+	m.Expect("eth_getUncleByBlockHashAndIndex", []interface{}{blockHash, "0x0"}).Return(uncle)
+	m.Expect("eth_getUncleByBlockNumberAndIndex", []interface{}{"0x40", "0x1"}).Return(nil)
+	e := eth.NewEth(m)
+
+	if count, err := e.GetUncleCountByBlockHash(blockHash); err != nil || count != 1 {
+		t.Errorf("Eth.GetUncleCountByBlockHash() = %d, %v, want 1", count, err)
+	}
+	got, err := e.GetUncleByBlockHashAndIndex(blockHash, 0)
+	if err != nil || got.Hash != uncle["hash"] || got.Number.Int64() != 63 {
+		t.Errorf("Eth.GetUncleByBlockHashAndIndex() = %+v, %v, want uncle 63", got, err)
+	}
+	if _, err := e.GetUncleByBlockNumberAndIndex("0x40", 1); err != eth.ErrNotFound {
+		t.Errorf("Eth.GetUncleByBlockNumberAndIndex() error = %v, want %v", err, eth.ErrNotFound)
+	}
+	if err := m.Verify(); err != nil {
+		t.Error(err)
+	}
+}
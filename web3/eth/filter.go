@@ -0,0 +1,279 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cleanunicorn/ethereum/provider"
+	"github.com/cleanunicorn/ethereum/web3/types"
+)
+
+// NewFilter installs a filter of the logs matching the query on the node and returns its id.
+// Poll it with GetFilterChanges, or use WatchLogs.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_newfilter
+func (c Eth) NewFilter(query types.FilterQuery) (string, error) {
+	return c.NewFilterContext(context.Background(), query)
+}
+
+// NewFilterContext is NewFilter giving up when the context is done.
+func (c Eth) NewFilterContext(ctx context.Context, query types.FilterQuery) (string, error) {
+	var filterID string
+	err := provider.CallResult(ctx, c.provider, &filterID, "eth_newFilter", []interface{}{query})
+	return filterID, err
+}
+
+// NewBlockFilter installs a filter of the new blocks on the node and returns its id.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_newblockfilter
+func (c Eth) NewBlockFilter() (string, error) {
+	return c.NewBlockFilterContext(context.Background())
+}
+
+// NewBlockFilterContext is NewBlockFilter giving up when the context is done.
+func (c Eth) NewBlockFilterContext(ctx context.Context) (string, error) {
+	var filterID string
+	err := provider.CallResult(ctx, c.provider, &filterID, "eth_newBlockFilter", []interface{}{})
+	return filterID, err
+}
+
+// NewPendingTransactionFilter installs a filter of the transactions entering the pending
+// pool on the node and returns its id.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_newpendingtransactionfilter
+func (c Eth) NewPendingTransactionFilter() (string, error) {
+	return c.NewPendingTransactionFilterContext(context.Background())
+}
+
+// NewPendingTransactionFilterContext is NewPendingTransactionFilter giving up when the context is done.
+func (c Eth) NewPendingTransactionFilterContext(ctx context.Context) (string, error) {
+	var filterID string
+	err := provider.CallResult(ctx, c.provider, &filterID, "eth_newPendingTransactionFilter", []interface{}{})
+	return filterID, err
+}
+
+// GetFilterChanges returns the changes of the filter since it was last polled.
+//
+// A filter the node forgot, usually because it was not polled for a few minutes,
+// fails with an error matching provider.ErrFilterNotFound.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getfilterchanges
+func (c Eth) GetFilterChanges(filterID string) (types.FilterChanges, error) {
+	return c.GetFilterChangesContext(context.Background(), filterID)
+}
+
+// GetFilterChangesContext is GetFilterChanges giving up when the context is done.
+func (c Eth) GetFilterChangesContext(ctx context.Context, filterID string) (types.FilterChanges, error) {
+	var changes types.FilterChanges
+	err := provider.CallResult(ctx, c.provider, &changes, "eth_getFilterChanges", []interface{}{filterID})
+	return changes, err
+}
+
+// GetFilterLogs returns all the logs matching the log filter.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getfilterlogs
+func (c Eth) GetFilterLogs(filterID string) ([]types.Log, error) {
+	return c.GetFilterLogsContext(context.Background(), filterID)
+}
+
+// GetFilterLogsContext is GetFilterLogs giving up when the context is done.
+func (c Eth) GetFilterLogsContext(ctx context.Context, filterID string) ([]types.Log, error) {
+	logs := []types.Log{}
+	err := provider.CallResult(ctx, c.provider, &logs, "eth_getFilterLogs", []interface{}{filterID})
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// UninstallFilter uninstalls the filter from the node, it returns false if the node
+// did not know the filter.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_uninstallfilter
+func (c Eth) UninstallFilter(filterID string) (bool, error) {
+	return c.UninstallFilterContext(context.Background(), filterID)
+}
+
+// UninstallFilterContext is UninstallFilter giving up when the context is done.
+func (c Eth) UninstallFilterContext(ctx context.Context, filterID string) (bool, error) {
+	var uninstalled bool
+	err := provider.CallResult(ctx, c.provider, &uninstalled, "eth_uninstallFilter", []interface{}{filterID})
+	return uninstalled, err
+}
+
+// WatchLogs installs a filter of the logs matching the query and polls it every interval.
+// The query should not have a block range, only the new logs are delivered.
+func (c Eth) WatchLogs(query types.FilterQuery, interval time.Duration) (*Filter, error) {
+	return c.WatchLogsContext(context.Background(), query, interval)
+}
+
+// WatchLogsContext is WatchLogs giving up installing the filter when the context is done.
+func (c Eth) WatchLogsContext(ctx context.Context, query types.FilterQuery, interval time.Duration) (*Filter, error) {
+	return c.watch(ctx, func(ctx context.Context) (string, error) {
+		return c.NewFilterContext(ctx, query)
+	}, interval)
+}
+
+// WatchBlocks installs a filter of the new blocks and polls it every interval.
+func (c Eth) WatchBlocks(interval time.Duration) (*Filter, error) {
+	return c.WatchBlocksContext(context.Background(), interval)
+}
+
+// WatchBlocksContext is WatchBlocks giving up installing the filter when the context is done.
+func (c Eth) WatchBlocksContext(ctx context.Context, interval time.Duration) (*Filter, error) {
+	return c.watch(ctx, c.NewBlockFilterContext, interval)
+}
+
+// WatchPendingTransactions installs a filter of the pending transactions and polls it every interval.
+func (c Eth) WatchPendingTransactions(interval time.Duration) (*Filter, error) {
+	return c.WatchPendingTransactionsContext(context.Background(), interval)
+}
+
+// WatchPendingTransactionsContext is WatchPendingTransactions giving up installing the
+// filter when the context is done.
+func (c Eth) WatchPendingTransactionsContext(ctx context.Context, interval time.Duration) (*Filter, error) {
+	return c.watch(ctx, c.NewPendingTransactionFilterContext, interval)
+}
+
+// Filter is a filter installed on the node and polled for its changes, the push-like
+// counterpart of a subscription for the providers without one, such as HTTP.
+//
+// The filter is installed again when the node forgets it, the changes made in
+// between can be missed. Close uninstalls it.
+type Filter struct {
+	eth      Eth
+	install  func(ctx context.Context) (string, error)
+	interval time.Duration
+
+	changes chan types.FilterChanges
+	errors  chan error
+	// ctx is canceled by Close, interrupting the call in flight
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	once     sync.Once
+	closeErr error
+
+	mu sync.Mutex
+	id string
+}
+
+func (c Eth) watch(ctx context.Context, install func(ctx context.Context) (string, error), interval time.Duration) (*Filter, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("polling interval must be positive, got %s", interval)
+	}
+
+	id, err := install(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Filter{
+		eth:      c,
+		install:  install,
+		interval: interval,
+		changes:  make(chan types.FilterChanges),
+		errors:   make(chan error, 1),
+		done:     make(chan struct{}),
+		id:       id,
+	}
+	f.ctx, f.cancel = context.WithCancel(context.Background())
+	go f.poll()
+
+	return f, nil
+}
+
+// ID returns the id of the filter on the node, which changes when the filter is installed again.
+func (f *Filter) ID() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.id
+}
+
+// Changes returns the channel delivering the changes found by each poll, the
+// empty polls are skipped. The channel is closed by Close.
+func (f *Filter) Changes() <-chan types.FilterChanges {
+	return f.changes
+}
+
+// Err returns a channel receiving the errors of the polls, polling goes on after them.
+// An error is dropped if the previous one was not received. The channel is closed by Close.
+func (f *Filter) Err() <-chan error {
+	return f.errors
+}
+
+// Close stops polling, interrupting the poll in flight, and uninstalls the filter.
+// Calling it again returns the error of the first call.
+func (f *Filter) Close() error {
+	f.once.Do(func() {
+		f.cancel()
+		<-f.done
+
+		_, f.closeErr = f.eth.UninstallFilter(f.ID())
+	})
+
+	return f.closeErr
+}
+
+// poll polls the filter every interval until Close is called.
+func (f *Filter) poll() {
+	defer func() {
+		close(f.changes)
+		close(f.errors)
+		close(f.done)
+	}()
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-f.ctx.Done():
+			return
+		}
+
+		changes, err := f.eth.GetFilterChangesContext(f.ctx, f.ID())
+		if errors.Is(err, provider.ErrFilterNotFound) {
+			err = f.reinstall()
+		}
+		if f.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			select {
+			case f.errors <- err:
+			default:
+			}
+			continue
+		}
+		if len(changes.Logs) == 0 && len(changes.Hashes) == 0 {
+			continue
+		}
+
+		select {
+		case f.changes <- changes:
+		case <-f.ctx.Done():
+			return
+		}
+	}
+}
+
+// reinstall installs the filter again, it is polled at the next interval.
+func (f *Filter) reinstall() error {
+	id, err := f.install(f.ctx)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.id = id
+	f.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package eth_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cleanunicorn/ethereum/provider"
+	"github.com/cleanunicorn/ethereum/web3/eth"
+	"github.com/cleanunicorn/ethereum/web3/types"
+)
+
+func TestEth_Watch_Interval(t *testing.T) {
+	tests := []struct {
+		name     string
+		watch    func(e eth.Eth, interval time.Duration) (*eth.Filter, error)
+		interval time.Duration
+	}{
+		{
+			name: "Logs without interval",
+			watch: func(e eth.Eth, interval time.Duration) (*eth.Filter, error) {
+				return e.WatchLogs(types.FilterQuery{}, interval)
+			},
+		},
+		{
+			name:     "Blocks with a negative interval",
+			watch:    eth.Eth.WatchBlocks,
+			interval: -time.Second,
+		},
+		{
+			name:  "Pending transactions without interval",
+			watch: eth.Eth.WatchPendingTransactions,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No filter is installed, the mock fails any call
+			m := provider.NewMockProvider()
+			if _, err := tt.watch(eth.NewEth(m), tt.interval); err == nil {
+				t.Errorf("Eth.Watch() error = nil, want an error for the interval %s", tt.interval)
+			}
+			if err := m.Verify(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestFilter_Close(t *testing.T) {
+	polled := make(chan struct{}, 1)
+	var uninstalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     uint64 `json:"id"`
+			Method string `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		result := `"0x1"`
+		switch req.Method {
+		case "eth_getFilterChanges":
+			// The node never answers, Close must not wait for it
+			select {
+			case polled <- struct{}{}:
+			default:
+			}
+			<-r.Context().Done()
+			return
+		case "eth_uninstallFilter":
+			atomic.AddInt32(&uninstalls, 1)
+			result = "true"
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%d,"result":%s}`, req.ID, result)
+	}))
+	defer server.Close()
+
+	filter, err := eth.NewEth(provider.DialHTTP(server.URL)).WatchBlocks(time.Millisecond)
+	if err != nil {
+		t.Fatalf("Eth.WatchBlocks() error = %v", err)
+	}
+	<-polled
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- filter.Close()
+	}()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Errorf("Filter.Close() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Filter.Close() blocked on the poll in flight")
+	}
+
+	if err := filter.Close(); err != nil {
+		t.Errorf("Filter.Close() again error = %v", err)
+	}
+	if got := atomic.LoadInt32(&uninstalls); got != 1 {
+		t.Errorf("eth_uninstallFilter called %d times, want 1", got)
+	}
+}
@@ -0,0 +1,93 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/cleanunicorn/ethereum/provider"
+	"github.com/cleanunicorn/ethereum/web3/types"
+)
+
+// GetLogs returns the logs matching the query.
+//
+// When the node refuses a block range as too large, see provider.ErrRangeTooLarge,
+// the range is split in halves queried one after the other until the node accepts them.
+//
+// See https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getlogs
+func (c Eth) GetLogs(query types.FilterQuery) ([]types.Log, error) {
+	return c.GetLogsContext(context.Background(), query)
+}
+
+// GetLogsContext is GetLogs giving up when the context is done.
+func (c Eth) GetLogsContext(ctx context.Context, query types.FilterQuery) ([]types.Log, error) {
+	logs := []types.Log{}
+	err := provider.CallResult(ctx, c.provider, &logs, "eth_getLogs", []interface{}{query})
+	if err == nil {
+		return logs, nil
+	}
+	if !errors.Is(err, provider.ErrRangeTooLarge) || query.BlockHash != "" {
+		return nil, err
+	}
+
+	from, to, ok := c.blockRange(ctx, query)
+	if !ok || from >= to {
+		return nil, err
+	}
+	middle := from + (to-from)/2
+
+	first := query
+	first.FromBlock = fmt.Sprintf("0x%x", from)
+	first.ToBlock = fmt.Sprintf("0x%x", middle)
+	logs, err = c.GetLogsContext(ctx, first)
+	if err != nil {
+		return nil, err
+	}
+
+	second := query
+	second.FromBlock = fmt.Sprintf("0x%x", middle+1)
+	second.ToBlock = fmt.Sprintf("0x%x", to)
+	more, err := c.GetLogsContext(ctx, second)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(logs, more...), nil
+}
+
+// blockRange returns the numbers of the blocks the query spans, false if they are not
+// known, such as for the pending block.
+func (c Eth) blockRange(ctx context.Context, query types.FilterQuery) (uint64, uint64, bool) {
+	var latest *uint64
+	number := func(tag string) (uint64, bool) {
+		switch tag {
+		case "earliest":
+			return 0, true
+		case "", "latest":
+			if latest == nil {
+				n, err := c.BlockNumberContext(ctx)
+				if err != nil || !n.IsUint64() {
+					return 0, false
+				}
+				latest = new(uint64)
+				*latest = n.Uint64()
+			}
+			return *latest, true
+		}
+
+		n, err := strconv.ParseUint(tag, 0, 64)
+		return n, err == nil
+	}
+
+	from, ok := number(query.FromBlock)
+	if !ok {
+		return 0, 0, false
+	}
+	to, ok := number(query.ToBlock)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return from, to, true
+}
@@ -0,0 +1,127 @@
+package eth_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cleanunicorn/ethereum/provider"
+	"github.com/cleanunicorn/ethereum/web3/eth"
+	"github.com/cleanunicorn/ethereum/web3/types"
+)
+
+func TestEth_GetLogs(t *testing.T) {
+	const transfer = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	const blockHash = "0x3d6122660cc824376f11ee842f83addc3525e2dd6756b9bcf0affa6aa88cf741"
+	logA := types.Log{Address: emptyAccount, BlockNumber: "0x1", Topics: []string{transfer}}
+	logB := types.Log{Address: emptyAccount, BlockNumber: "0x3", Topics: []string{transfer}}
+	tooLarge := `{"code":-32005,"message":"query returned more than 10000 results"}`
+
+	type call struct {
+		method string
+		params string
+		result string
+	}
+	tests := []struct {
+		name    string
+		query   types.FilterQuery
+		calls   []call
+		want    []types.Log
+		wantErr error
+	}{
+		{
+			name: "Topics with wildcards",
+			query: types.FilterQuery{
+				FromBlock: "0x1",
+				ToBlock:   "latest",
+				Addresses: []string{emptyAccount},
+				Topics:    [][]string{{transfer}, {}, {account0, account1}, {}},
+			},
+			calls: []call{
+				{"eth_getLogs", `[{"fromBlock":"0x1","toBlock":"latest","address":"` + emptyAccount + `","topics":["` + transfer + `",null,["` + account0 + `","` + account1 + `"]]}]`, `[]`},
+			},
+			want: []types.Log{},
+		},
+		{
+			name:  "Block hash",
+			query: types.FilterQuery{BlockHash: blockHash, FromBlock: "0x1", Addresses: []string{emptyAccount, zeroAccount}},
+			calls: []call{
+				{"eth_getLogs", `[{"blockHash":"` + blockHash + `","address":["` + emptyAccount + `","` + zeroAccount + `"]}]`, `[{"address":"` + emptyAccount + `","blockNumber":"0x1","topics":["` + transfer + `"]}]`},
+			},
+			want: []types.Log{logA},
+		},
+		{
+			name:  "Range split",
+			query: types.FilterQuery{FromBlock: "0x0", ToBlock: "0x3"},
+			calls: []call{
+				{"eth_getLogs", `[{"fromBlock":"0x0","toBlock":"0x3"}]`, tooLarge},
+				{"eth_getLogs", `[{"fromBlock":"0x0","toBlock":"0x1"}]`, `[{"address":"` + emptyAccount + `","blockNumber":"0x1","topics":["` + transfer + `"]}]`},
+				{"eth_getLogs", `[{"fromBlock":"0x2","toBlock":"0x3"}]`, tooLarge},
+				{"eth_getLogs", `[{"fromBlock":"0x2","toBlock":"0x2"}]`, `[]`},
+				{"eth_getLogs", `[{"fromBlock":"0x3","toBlock":"0x3"}]`, `[{"address":"` + emptyAccount + `","blockNumber":"0x3","topics":["` + transfer + `"]}]`},
+			},
+			want: []types.Log{logA, logB},
+		},
+		{
+			name:  "Range split up to the latest block",
+			query: types.FilterQuery{FromBlock: "0x2"},
+			calls: []call{
+				{"eth_getLogs", `[{"fromBlock":"0x2"}]`, tooLarge},
+				{"eth_blockNumber", `[]`, `"0x3"`},
+				{"eth_getLogs", `[{"fromBlock":"0x2","toBlock":"0x2"}]`, `[]`},
+				{"eth_getLogs", `[{"fromBlock":"0x3","toBlock":"0x3"}]`, `[{"address":"` + emptyAccount + `","blockNumber":"0x3","topics":["` + transfer + `"]}]`},
+			},
+			want: []types.Log{logB},
+		},
+		{
+			name:  "Single block too large",
+			query: types.FilterQuery{FromBlock: "0x2", ToBlock: "0x2"},
+			calls: []call{
+				{"eth_getLogs", `[{"fromBlock":"0x2","toBlock":"0x2"}]`, tooLarge},
+			},
+			wantErr: provider.ErrRangeTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := provider.NewMockProvider().InOrder()
+			for _, call := range tt.calls {
+				e := m.Expect(call.method, json.RawMessage(call.params))
+				var rpcErr provider.RPCError
+				if json.Unmarshal([]byte(call.result), &rpcErr) == nil && rpcErr.Code != 0 {
+					e.ReturnError(rpcErr.Code, rpcErr.Message)
+				} else {
+					e.Return(json.RawMessage(call.result))
+				}
+			}
+			got, err := eth.NewEth(m).GetLogs(tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Eth.GetLogs() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Eth.GetLogs() = %+v, want %+v", got, tt.want)
+			}
+			if err := m.Verify(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestEth_GetLogs_Retry(t *testing.T) {
+	m := provider.NewMockProvider().InOrder()
+	m.Expect("eth_getLogs", json.RawMessage(`[{"fromBlock":"0x0","toBlock":"0x1"}]`)).ReturnError(-32005, "query returned more than 10000 results")
+	m.Expect("eth_getLogs", json.RawMessage(`[{"fromBlock":"0x0","toBlock":"0x0"}]`)).Return([]types.Log{})
+	m.Expect("eth_getLogs", json.RawMessage(`[{"fromBlock":"0x1","toBlock":"0x1"}]`)).Return([]types.Log{})
+
+	// The range is split at once, the retries would send the same query again
+	p := provider.NewRetryProvider(m, provider.RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	if _, err := eth.NewEth(p).GetLogs(types.FilterQuery{FromBlock: "0x0", ToBlock: "0x1"}); err != nil {
+		t.Fatalf("Eth.GetLogs() error = %v", err)
+	}
+	if err := m.Verify(); err != nil {
+		t.Error(err)
+	}
+}
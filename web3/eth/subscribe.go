@@ -0,0 +1,107 @@
+package eth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cleanunicorn/ethereum/provider"
+	"github.com/cleanunicorn/ethereum/web3/types"
+)
+
+// SubscribeNewHeads sends every new block header on ch.
+// The header is decoded as a block without transactions.
+//
+// The provider needs to support subscriptions, see provider.DialWebSocket.
+func (c Eth) SubscribeNewHeads(ch chan<- types.Block) (*provider.Subscription, error) {
+	return c.SubscribeNewHeadsContext(context.Background(), ch)
+}
+
+// SubscribeNewHeadsContext is SubscribeNewHeads giving up when the context is done.
+func (c Eth) SubscribeNewHeadsContext(ctx context.Context, ch chan<- types.Block) (*provider.Subscription, error) {
+	return c.subscribe(ctx, func(result json.RawMessage, done <-chan struct{}) error {
+		var b types.Block
+		if err := json.Unmarshal(result, &b); err != nil {
+			return err
+		}
+		b.RawTransactions = json.RawMessage(`{}`)
+
+		select {
+		case ch <- b:
+		case <-done:
+		}
+		return nil
+	}, "newHeads")
+}
+
+// SubscribeNewPendingTransactions sends the hash of every transaction entering the pending pool on ch.
+//
+// The provider needs to support subscriptions, see provider.DialWebSocket.
+func (c Eth) SubscribeNewPendingTransactions(ch chan<- string) (*provider.Subscription, error) {
+	return c.SubscribeNewPendingTransactionsContext(context.Background(), ch)
+}
+
+// SubscribeNewPendingTransactionsContext is SubscribeNewPendingTransactions giving up when the context is done.
+func (c Eth) SubscribeNewPendingTransactionsContext(ctx context.Context, ch chan<- string) (*provider.Subscription, error) {
+	return c.subscribe(ctx, func(result json.RawMessage, done <-chan struct{}) error {
+		var hash string
+		if err := json.Unmarshal(result, &hash); err != nil {
+			return err
+		}
+
+		select {
+		case ch <- hash:
+		case <-done:
+		}
+		return nil
+	}, "newPendingTransactions")
+}
+
+// SubscribeLogs sends every log matching query on ch.
+// The block range of the query is ignored by the nodes, only the new logs are sent.
+//
+// The provider needs to support subscriptions, see provider.DialWebSocket.
+func (c Eth) SubscribeLogs(ch chan<- types.Log, query types.FilterQuery) (*provider.Subscription, error) {
+	return c.SubscribeLogsContext(context.Background(), ch, query)
+}
+
+// SubscribeLogsContext is SubscribeLogs giving up when the context is done.
+func (c Eth) SubscribeLogsContext(ctx context.Context, ch chan<- types.Log, query types.FilterQuery) (*provider.Subscription, error) {
+	return c.subscribe(ctx, func(result json.RawMessage, done <-chan struct{}) error {
+		var l types.Log
+		if err := json.Unmarshal(result, &l); err != nil {
+			return err
+		}
+
+		select {
+		case ch <- l:
+		case <-done:
+		}
+		return nil
+	}, "logs", query)
+}
+
+// subscribe subscribes and passes each notification to deliver, which gives up sending
+// it when done is closed. The notifications deliver fails to decode are reported on the
+// Err channel of the subscription.
+func (c Eth) subscribe(ctx context.Context, deliver func(result json.RawMessage, done <-chan struct{}) error, args ...interface{}) (*provider.Subscription, error) {
+	subscriber, ok := c.provider.(provider.Subscriber)
+	if !ok {
+		return nil, provider.ErrSubscriptionNotSupported
+	}
+
+	sub, err := subscriber.SubscribeContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for result := range sub.Notifications() {
+			if err := deliver(result, sub.Done()); err != nil {
+				sub.ReportError(fmt.Errorf("decoding %s notification: %w", args[0], err))
+			}
+		}
+	}()
+
+	return sub, nil
+}
@@ -0,0 +1,140 @@
+package eth_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cleanunicorn/ethereum/provider"
+	"github.com/cleanunicorn/ethereum/web3/eth"
+	"github.com/cleanunicorn/ethereum/web3/types"
+	"github.com/gorilla/websocket"
+)
+
+// startSubscriptionNode starts a WebSocket JSON-RPC stand-in sending a notification which
+// is not an object, then an object with the subscription id as hash and address, after each
+// eth_subscribe.
+func startSubscriptionNode(t *testing.T) (string, func()) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Could not upgrade connection, err: %s", err)
+			return
+		}
+		defer conn.Close()
+
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var req struct {
+				ID     uint64 `json:"id"`
+				Method string `json:"method"`
+			}
+			if err := json.Unmarshal(message, &req); err != nil {
+				t.Errorf("Could not decode request, err: %s", err)
+				return
+			}
+
+			switch req.Method {
+			case "eth_subscribe":
+				id := fmt.Sprintf("0x%x", req.ID)
+				conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":"%s"}`, req.ID, id)))
+				conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"%s","result":"0x1"}}`, id)))
+				conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"%s","result":{"hash":"%s","address":"%s"}}}`, id, id, id)))
+			case "eth_unsubscribe":
+				conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":true}`, req.ID)))
+			}
+		}
+	}))
+
+	return "ws" + strings.TrimPrefix(server.URL, "http"), server.Close
+}
+
+func TestEth_SubscribeNewHeads(t *testing.T) {
+	endpoint, stop := startSubscriptionNode(t)
+	defer stop()
+
+	p, err := provider.DialWebSocket(endpoint)
+	if err != nil {
+		t.Fatalf("DialWebSocket() error = %v", err)
+	}
+	defer p.Close()
+	e := eth.NewEth(p)
+
+	// Nobody reads the headers of the first subscription, unsubscribing must not block the others
+	abandoned, err := e.SubscribeNewHeads(make(chan types.Block))
+	if err != nil {
+		t.Fatalf("Eth.SubscribeNewHeads() error = %v", err)
+	}
+	if err := abandoned.Unsubscribe(); err != nil {
+		t.Errorf("Subscription.Unsubscribe() error = %v", err)
+	}
+
+	heads := make(chan types.Block)
+	sub, err := e.SubscribeNewHeads(heads)
+	if err != nil {
+		t.Fatalf("Eth.SubscribeNewHeads() error = %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	select {
+	case err := <-sub.Err():
+		if err == nil || !strings.Contains(err.Error(), "newHeads") {
+			t.Errorf("Subscription.Err() = %v, want the error decoding the notification", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the error decoding the notification")
+	}
+
+	select {
+	case head := <-heads:
+		if head.Hash != sub.ID {
+			t.Errorf("Eth.SubscribeNewHeads() header hash = %s, want %s", head.Hash, sub.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the header")
+	}
+}
+
+func TestEth_SubscribeLogs(t *testing.T) {
+	endpoint, stop := startSubscriptionNode(t)
+	defer stop()
+
+	p, err := provider.DialWebSocket(endpoint)
+	if err != nil {
+		t.Fatalf("DialWebSocket() error = %v", err)
+	}
+	defer p.Close()
+
+	logs := make(chan types.Log)
+	sub, err := eth.NewEth(p).SubscribeLogs(logs, types.FilterQuery{Addresses: []string{emptyAccount}})
+	if err != nil {
+		t.Fatalf("Eth.SubscribeLogs() error = %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	select {
+	case err := <-sub.Err():
+		if err == nil || !strings.Contains(err.Error(), "logs") {
+			t.Errorf("Subscription.Err() = %v, want the error decoding the notification", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the error decoding the notification")
+	}
+
+	select {
+	case l := <-logs:
+		if l.Address != sub.ID {
+			t.Errorf("Eth.SubscribeLogs() log address = %s, want %s", l.Address, sub.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timed out waiting for the log")
+	}
+}
@@ -1,6 +1,7 @@
 package net
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/cleanunicorn/ethereum/provider"
@@ -29,7 +30,12 @@ type ResponseNetVersion struct {
 //
 // See https://github.com/ethereum/wiki/wiki/JSON-RPC#net_version
 func (c Net) Version() (int64, error) {
-	reply, err := c.provider.Call("net_version", []interface{}{})
+	return c.VersionContext(context.Background())
+}
+
+// VersionContext is Version giving up when the context is done.
+func (c Net) VersionContext(ctx context.Context) (int64, error) {
+	reply, err := c.provider.CallContext(ctx, "net_version", []interface{}{})
 	if err != nil {
 		return 0, err
 	}
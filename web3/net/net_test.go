@@ -1,17 +1,23 @@
 package net_test
 
 import (
-	"fmt"
-	"os/exec"
-	"strings"
+	"flag"
+	"math/big"
+	"path/filepath"
 	"testing"
 
 	"github.com/cleanunicorn/ethereum/provider"
+	"github.com/cleanunicorn/ethereum/testnode"
 	"github.com/cleanunicorn/ethereum/web3"
+	"github.com/ethereum/go-ethereum/common"
 )
 
+var update = flag.Bool("update", false, "update golden files")
+
 func TestHTTPClient_Net_version(t *testing.T) {
-	defer startGanache(t)()
+	if *update {
+		defer startTestNode(t)()
+	}
 
 	type fields struct {
 		url string
@@ -35,7 +41,9 @@ func TestHTTPClient_Net_version(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := web3.NewClient(provider.DialHTTP(tt.endpoint))
+			p := testProvider(t, tt.name, tt.endpoint)
+			defer p.Close()
+			c := web3.NewClient(p)
 			got, err := c.Net.Version()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("JSONRPCEthereumServer.Net_version() error = %v, wantErr %v", err, tt.wantErr)
@@ -64,41 +72,42 @@ const ganacheAccount7 = "0x417f0549f0c8afbf8e440bfca721905adc51c128"
 const ganacheAccount8 = "0x95355382c7d4bcae94df7f0ff1178b325bd7512b"
 const ganacheAccount9 = "0x0ed774f495f902952dca2ce019241433c0088686"
 
-func startGanache(t *testing.T) func() {
-	// Make sure ganache is stopped
-	ganacheStop := exec.Command("docker", []string{"rm", "-f", "ganache"}...)
-	ganacheStop.Start()
-	ganacheStop.Wait()
+// testProvider returns a provider replaying the calls recorded for the test in
+// test-fixtures/<name>.rpc.golden. With -update the calls are made to the endpoint
+// and recorded again.
+func testProvider(t *testing.T, name string, endpoint string) provider.Provider {
+	fixture := filepath.Join("test-fixtures/", name+".rpc.golden")
+	if *update {
+		return provider.NewRecordProvider(provider.DialHTTP(endpoint), fixture)
+	}
 
-	// Start Ganache
-	command := "docker"
-	args := fmt.Sprintf("run --net=host --name=ganache --rm trufflesuite/ganache-cli -s 99 -i %d -p %s", testGanacheNetworkID, ganachePort)
-	ganache := exec.Command(command, strings.Split(args, " ")...)
-	ganacheOut, err := ganache.StdoutPipe()
+	p, err := provider.NewReplayProvider(fixture)
 	if err != nil {
-		t.Fatal(err)
-	}
-	if err := ganache.Start(); err != nil {
-		t.Fatal(err)
+		t.Fatal("Could not load recorded calls, err: ", err)
 	}
+	return p
+}
 
-	buff := make([]byte, 10000)
-	var output string
-	for {
-		n, err := ganacheOut.Read(buff)
-		if err != nil {
-			t.Fatal("Error reading output, err = ", err)
-		}
-		output = output + string(buff[:n])
-
-		if strings.Contains(output, "Listening on") {
-			break
-		}
+// startTestNode starts an in-process node at testGanacheHTTPEndpoint, with the network id
+// and the funded accounts of the ganache instance the tests were written against.
+func startTestNode(t *testing.T) func() {
+	balance, _ := new(big.Int).SetString("100000000000000000000", 10)
+	alloc := map[common.Address]*big.Int{}
+	for _, a := range []string{
+		ganacheAccount0, ganacheAccount1, ganacheAccount2, ganacheAccount3, ganacheAccount4,
+		ganacheAccount5, ganacheAccount6, ganacheAccount7, ganacheAccount8, ganacheAccount9,
+	} {
+		alloc[common.HexToAddress(a)] = balance
 	}
 
-	return func() {
-		ganache.Process.Kill()
-		rmContainer := exec.Command("docker", "rm", "-f", "ganache")
-		rmContainer.Run()
+	node, err := testnode.Start(testnode.Config{
+		ChainID: testGanacheNetworkID,
+		Alloc:   alloc,
+		Addr:    "127.0.0.1:" + ganachePort,
+	})
+	if err != nil {
+		t.Fatal(err)
 	}
+
+	return node.Close
 }
@@ -0,0 +1,110 @@
+package types
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// CallMsg is a transaction executed by eth_call and eth_estimateGas without being sent.
+// The zero fields are left out, the node picks their defaults.
+type CallMsg struct {
+	From     string
+	To       string // empty to create a contract
+	Gas      uint64
+	GasPrice *big.Int
+	// MaxFeePerGas and MaxPriorityFeePerGas replace GasPrice for EIP-1559 transactions
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	Value                *big.Int
+	Data                 []byte
+	AccessList           AccessList
+}
+
+// AccessList is the list of the addresses and storage keys an EIP-2930 transaction accesses.
+type AccessList []AccessTuple
+
+// AccessTuple is an address and its storage keys in an access list.
+type AccessTuple struct {
+	Address     string   `json:"address"`
+	StorageKeys []string `json:"storageKeys"`
+}
+
+// MarshalJSON encodes the message as the transaction object of the JSON-RPC API.
+func (msg CallMsg) MarshalJSON() ([]byte, error) {
+	args := struct {
+		From                 string     `json:"from,omitempty"`
+		To                   string     `json:"to,omitempty"`
+		Gas                  string     `json:"gas,omitempty"`
+		GasPrice             string     `json:"gasPrice,omitempty"`
+		MaxFeePerGas         string     `json:"maxFeePerGas,omitempty"`
+		MaxPriorityFeePerGas string     `json:"maxPriorityFeePerGas,omitempty"`
+		Value                string     `json:"value,omitempty"`
+		Data                 string     `json:"data,omitempty"`
+		AccessList           AccessList `json:"accessList,omitempty"`
+	}{
+		From:                 msg.From,
+		To:                   msg.To,
+		GasPrice:             encodeBig(msg.GasPrice),
+		MaxFeePerGas:         encodeBig(msg.MaxFeePerGas),
+		MaxPriorityFeePerGas: encodeBig(msg.MaxPriorityFeePerGas),
+		Value:                encodeBig(msg.Value),
+		AccessList:           msg.AccessList,
+	}
+	if msg.Gas != 0 {
+		args.Gas = fmt.Sprintf("0x%x", msg.Gas)
+	}
+	if len(msg.Data) > 0 {
+		args.Data = "0x" + hex.EncodeToString(msg.Data)
+	}
+
+	return json.Marshal(args)
+}
+
+// StateOverride replaces the state of accounts, by address, for a single eth_call.
+type StateOverride map[string]OverrideAccount
+
+// OverrideAccount is the state replacing the state of an account, the nil fields are kept.
+//
+// State replaces the whole storage of the account while StateDiff only replaces the
+// given slots, they can not be used together.
+type OverrideAccount struct {
+	Nonce     *uint64
+	Code      []byte
+	Balance   *big.Int
+	State     map[string]string
+	StateDiff map[string]string
+}
+
+// MarshalJSON encodes the account as expected by the state override set of eth_call.
+func (a OverrideAccount) MarshalJSON() ([]byte, error) {
+	account := struct {
+		Nonce     string            `json:"nonce,omitempty"`
+		Code      string            `json:"code,omitempty"`
+		Balance   string            `json:"balance,omitempty"`
+		State     map[string]string `json:"state,omitempty"`
+		StateDiff map[string]string `json:"stateDiff,omitempty"`
+	}{
+		Balance:   encodeBig(a.Balance),
+		State:     a.State,
+		StateDiff: a.StateDiff,
+	}
+	if a.Nonce != nil {
+		account.Nonce = fmt.Sprintf("0x%x", *a.Nonce)
+	}
+	if a.Code != nil {
+		account.Code = "0x" + hex.EncodeToString(a.Code)
+	}
+
+	return json.Marshal(account)
+}
+
+// encodeBig returns the hex quantity of n, empty if n is nil.
+func encodeBig(n *big.Int) string {
+	if n == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("0x%x", n)
+}
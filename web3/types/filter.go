@@ -0,0 +1,102 @@
+package types
+
+import "encoding/json"
+
+// FilterQuery selects the logs returned by eth_getLogs and the log filters.
+//
+// The logs are searched either in the single block with BlockHash or in the blocks
+// from FromBlock to ToBlock, block numbers or tags such as "latest", "latest" by default.
+//
+// Topics matches the topics of the logs by position: a log matches if each of its
+// topics is one of the alternatives given at the same position. An empty position
+// matches any topic, for example
+//
+//	[][]string{{transferEvent}, {}, {alice, bob}}
+//
+// matches the transfers to alice or bob.
+type FilterQuery struct {
+	BlockHash string
+	FromBlock string
+	ToBlock   string
+	// Addresses restricts the logs to the ones emitted by the contracts, any contract if empty
+	Addresses []string
+	Topics    [][]string
+}
+
+// MarshalJSON encodes the query as the filter object of the JSON-RPC API.
+func (q FilterQuery) MarshalJSON() ([]byte, error) {
+	filter := struct {
+		BlockHash string        `json:"blockHash,omitempty"`
+		FromBlock string        `json:"fromBlock,omitempty"`
+		ToBlock   string        `json:"toBlock,omitempty"`
+		Address   interface{}   `json:"address,omitempty"`
+		Topics    []interface{} `json:"topics,omitempty"`
+	}{
+		BlockHash: q.BlockHash,
+	}
+	if q.BlockHash == "" {
+		filter.FromBlock = q.FromBlock
+		filter.ToBlock = q.ToBlock
+	}
+
+	switch len(q.Addresses) {
+	case 0:
+	case 1:
+		filter.Address = q.Addresses[0]
+	default:
+		filter.Address = q.Addresses
+	}
+
+	// The wildcards at the end are left out, they match anything anyway
+	last := len(q.Topics) - 1
+	for last >= 0 && len(q.Topics[last]) == 0 {
+		last--
+	}
+	for _, alternatives := range q.Topics[:last+1] {
+		switch len(alternatives) {
+		case 0:
+			filter.Topics = append(filter.Topics, nil)
+		case 1:
+			filter.Topics = append(filter.Topics, alternatives[0])
+		default:
+			filter.Topics = append(filter.Topics, alternatives)
+		}
+	}
+
+	return json.Marshal(filter)
+}
+
+// FilterChanges are the changes of a filter since it was last polled: the new logs of
+// a log filter, or the hashes of the new blocks or pending transactions.
+type FilterChanges struct {
+	Logs   []Log
+	Hashes []string
+}
+
+// UnmarshalJSON decodes the result of eth_getFilterChanges, a list of logs or of hashes.
+func (c *FilterChanges) UnmarshalJSON(data []byte) error {
+	var changes []json.RawMessage
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return err
+	}
+
+	*c = FilterChanges{}
+	for _, change := range changes {
+		if len(change) > 0 && change[0] == '"' {
+			var hash string
+			if err := json.Unmarshal(change, &hash); err != nil {
+				return err
+			}
+			c.Hashes = append(c.Hashes, hash)
+			continue
+		}
+
+		var log Log
+		if err := json.Unmarshal(change, &log); err != nil {
+			return err
+		}
+		c.Logs = append(c.Logs, log)
+	}
+
+	return nil
+}
@@ -54,21 +54,26 @@ type Receipt struct {
 	ContractAddress   string `json:"contractAddress"`
 	CumulativeGasUsed string `json:"cumulativeGasUsed"`
 	GasUsed           string `json:"gasUsed"`
-	Logs              []struct {
-		Address             string   `json:"address"`
-		BlockHash           string   `json:"blockHash"`
-		BlockNumber         string   `json:"blockNumber"`
-		Data                string   `json:"data"`
-		LogIndex            string   `json:"logIndex"`
-		Topics              []string `json:"topics"`
-		TransactionHash     string   `json:"transactionHash"`
-		TransactionIndex    string   `json:"transactionIndex"`
-		TransactionLogIndex string   `json:"transactionLogIndex"`
-		Type                string   `json:"type"`
-	} `json:"logs"`
-	LogsBloom        string `json:"logsBloom"`
-	Root             string `json:"root"`
-	Status           string `json:"status"`
-	TransactionHash  string `json:"transactionHash"`
-	TransactionIndex string `json:"transactionIndex"`
+	Logs              []Log  `json:"logs"`
+	LogsBloom         string `json:"logsBloom"`
+	Root              string `json:"root"`
+	Status            string `json:"status"`
+	TransactionHash   string `json:"transactionHash"`
+	TransactionIndex  string `json:"transactionIndex"`
+}
+
+// Log represents an event emitted by a contract
+type Log struct {
+	Address             string   `json:"address"`
+	BlockHash           string   `json:"blockHash"`
+	BlockNumber         string   `json:"blockNumber"`
+	Data                string   `json:"data"`
+	LogIndex            string   `json:"logIndex"`
+	Topics              []string `json:"topics"`
+	TransactionHash     string   `json:"transactionHash"`
+	TransactionIndex    string   `json:"transactionIndex"`
+	TransactionLogIndex string   `json:"transactionLogIndex"`
+	Type                string   `json:"type"`
+	// Removed is true when the log was removed by a chain reorganization
+	Removed bool `json:"removed"`
 }
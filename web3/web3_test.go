@@ -7,13 +7,12 @@ import (
 	"io/ioutil"
 	"math/big"
 	"net/http"
-	"os/exec"
 	"path/filepath"
 	"reflect"
-	"strings"
 	"testing"
 
 	"github.com/cleanunicorn/ethereum/provider"
+	"github.com/cleanunicorn/ethereum/testnode"
 	"github.com/cleanunicorn/ethereum/web3"
 
 	"github.com/cleanunicorn/ethereum/core"
@@ -26,7 +25,9 @@ import (
 var update = flag.Bool("update", false, "update golden files")
 
 func TestHTTPClient_Eth_getBalance(t *testing.T) {
-	defer startGanache(t)()
+	if *update {
+		defer startTestNode(t)()
+	}
 
 	type fields struct {
 		HTTP string
@@ -81,7 +82,9 @@ func TestHTTPClient_Eth_getBalance(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := web3.NewClient(provider.DialHTTP(tt.fields.HTTP))
+			p := testProvider(t, tt.name, tt.fields.HTTP)
+			defer p.Close()
+			c := web3.NewClient(p)
 			got, err := c.Eth.GetBalance(tt.args.account, tt.args.block)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Client.Eth_getBalance() error = %v, wantErr %v", err, tt.wantErr)
@@ -135,7 +138,9 @@ func TestHTTPClient_Eth_getBlockByNumber(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := web3.NewClient(provider.DialHTTP(tt.endpoint))
+			p := testProvider(t, tt.name, tt.endpoint)
+			defer p.Close()
+			c := web3.NewClient(p)
 			got, err := c.Eth.GetBlockByNumber(tt.args.blockNumberHex, tt.args.includeTransactions)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("HTTPClient.Eth_getBlockByNumber() error = %v, wantErr %v", err, tt.wantErr)
@@ -177,7 +182,9 @@ func TestHTTPClient_Eth_blockNumber(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := web3.NewClient(provider.DialHTTP(tt.endpoint))
+			p := testProvider(t, tt.name, tt.endpoint)
+			defer p.Close()
+			c := web3.NewClient(p)
 			got, err := c.Eth.BlockNumber()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("HTTPClient.Eth_blockNumber() error = %v, wantErr %v", err, tt.wantErr)
@@ -191,7 +198,9 @@ func TestHTTPClient_Eth_blockNumber(t *testing.T) {
 }
 
 func TestHTTPClient_Eth_getTransactionCount(t *testing.T) {
-	defer startGanache(t)()
+	if *update {
+		defer startTestNode(t)()
+	}
 
 	type args struct {
 		account string
@@ -225,7 +234,9 @@ func TestHTTPClient_Eth_getTransactionCount(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := web3.NewClient(provider.DialHTTP(tt.endpoint))
+			p := testProvider(t, tt.name, tt.endpoint)
+			defer p.Close()
+			c := web3.NewClient(p)
 			got, err := c.Eth.GetTransactionCount(tt.args.account, tt.args.block)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("HTTPClient.Eth_getTransactionCount() error = %v, wantErr %v", err, tt.wantErr)
@@ -239,7 +250,7 @@ func TestHTTPClient_Eth_getTransactionCount(t *testing.T) {
 }
 
 func TestHTTPClient_Eth_sendRawTransaction(t *testing.T) {
-	defer startGanache(t)()
+	defer startTestNode(t)()
 
 	type args struct {
 		signedTransaction string
@@ -298,8 +309,6 @@ func TestHTTPClient_Eth_sendRawTransaction(t *testing.T) {
 }
 
 func TestHTTPClient_Eth_getTransactionReceipt(t *testing.T) {
-	defer startGanache(t)()
-
 	type fields struct {
 		client   *http.Client
 		endpoint string
@@ -324,7 +333,9 @@ func TestHTTPClient_Eth_getTransactionReceipt(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			c := web3.NewClient(provider.DialHTTP(tt.endpoint))
+			p := testProvider(t, tt.name, tt.endpoint)
+			defer p.Close()
+			c := web3.NewClient(p)
 			got, err := c.Eth.GetTransactionReceipt(tt.args.transactionHash)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("HTTPClient.Eth_getTransactionReceipt() error = %v, wantErr %v", err, tt.wantErr)
@@ -368,41 +379,42 @@ const ganacheAccount7 = "0x417f0549f0c8afbf8e440bfca721905adc51c128"
 const ganacheAccount8 = "0x95355382c7d4bcae94df7f0ff1178b325bd7512b"
 const ganacheAccount9 = "0x0ed774f495f902952dca2ce019241433c0088686"
 
-func startGanache(t *testing.T) func() {
-	// Make sure ganache is stopped
-	ganacheStop := exec.Command("docker", []string{"rm", "-f", "ganache"}...)
-	ganacheStop.Start()
-	ganacheStop.Wait()
+// testProvider returns a provider replaying the calls recorded for the test in
+// test-fixtures/<name>.rpc.golden. With -update the calls are made to the endpoint
+// and recorded again.
+func testProvider(t *testing.T, name string, endpoint string) provider.Provider {
+	fixture := filepath.Join("test-fixtures/", name+".rpc.golden")
+	if *update {
+		return provider.NewRecordProvider(provider.DialHTTP(endpoint), fixture)
+	}
 
-	// Start Ganache
-	command := "docker"
-	args := fmt.Sprintf("run --net=host --name=ganache --rm trufflesuite/ganache-cli -s 99 -i %d -p %s", testGanacheNetworkID, ganachePort)
-	ganache := exec.Command(command, strings.Split(args, " ")...)
-	ganacheOut, err := ganache.StdoutPipe()
+	p, err := provider.NewReplayProvider(fixture)
 	if err != nil {
-		t.Fatal(err)
-	}
-	if err := ganache.Start(); err != nil {
-		t.Fatal(err)
+		t.Fatal("Could not load recorded calls, err: ", err)
 	}
+	return p
+}
 
-	buff := make([]byte, 10000)
-	var output string
-	for {
-		n, err := ganacheOut.Read(buff)
-		if err != nil {
-			t.Fatal("Error reading output, err = ", err)
-		}
-		output = output + string(buff[:n])
-
-		if strings.Contains(output, "Listening on") {
-			break
-		}
+// startTestNode starts an in-process node at testGanacheHTTPEndpoint, with the network id
+// and the funded accounts of the ganache instance the tests were written against.
+func startTestNode(t *testing.T) func() {
+	balance, _ := new(big.Int).SetString("100000000000000000000", 10)
+	alloc := map[common.Address]*big.Int{}
+	for _, a := range []string{
+		ganacheAccount0, ganacheAccount1, ganacheAccount2, ganacheAccount3, ganacheAccount4,
+		ganacheAccount5, ganacheAccount6, ganacheAccount7, ganacheAccount8, ganacheAccount9,
+	} {
+		alloc[common.HexToAddress(a)] = balance
 	}
 
-	return func() {
-		ganache.Process.Kill()
-		rmContainer := exec.Command("docker", "rm", "-f", "ganache")
-		rmContainer.Run()
+	node, err := testnode.Start(testnode.Config{
+		ChainID: testGanacheNetworkID,
+		Alloc:   alloc,
+		Addr:    "127.0.0.1:" + ganachePort,
+	})
+	if err != nil {
+		t.Fatal(err)
 	}
+
+	return node.Close
 }